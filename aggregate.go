@@ -10,6 +10,16 @@ func Count(clause Clause) AggregateClause {
 	return Aggregate("COUNT", clause)
 }
 
+// CountStar function generates "count(*)" statement
+func CountStar() AggregateClause {
+	return Count(SQLText("*"))
+}
+
+// CountDistinct function generates "count(distinct %s)" statement for clause
+func CountDistinct(clause Clause) AggregateClause {
+	return Count(clause).Distinct()
+}
+
 // Sum function generates "sum(%s)" statement for clause
 func Sum(clause Clause) AggregateClause {
 	return Aggregate("SUM", clause)
@@ -25,15 +35,88 @@ func Max(clause Clause) AggregateClause {
 	return Aggregate("MAX", clause)
 }
 
+// ArrayAgg function generates "array_agg(%s)" statement for clause
+// NOTE: array_agg is postgres specific
+func ArrayAgg(clause Clause) AggregateClause {
+	return Aggregate("ARRAY_AGG", clause)
+}
+
+// StringAgg function generates "string_agg(%s, '<separator>')" statement for
+// clause
+// NOTE: string_agg is postgres specific
+func StringAgg(clause Clause, separator string) AggregateClause {
+	aggregate := Aggregate("STRING_AGG", clause)
+	aggregate.args = []Clause{StringLiteral(separator)}
+	return aggregate
+}
+
 // Aggregate generates a new aggregate clause given function & clause
 func Aggregate(fn string, clause Clause) AggregateClause {
-	return AggregateClause{fn, clause}
+	return AggregateClause{fn: fn, clause: clause}
 }
 
 // AggregateClause is the base struct for building aggregate functions
 type AggregateClause struct {
-	fn     string
-	clause Clause
+	fn       string
+	clause   Clause
+	args     []Clause
+	distinct bool
+	orderBy  *OrderByClause
+	filter   *WhereClause
+}
+
+// Distinct makes the aggregate function ignore the duplicated values:
+// SUM(DISTINCT x)
+// With an OrderBy, the aggregated values can only be ordered by themselves:
+// STRING_AGG(DISTINCT x, ',' ORDER BY x)
+func (c AggregateClause) Distinct() AggregateClause {
+	c.distinct = true
+	return c
+}
+
+// Filter restricts the rows fed to the aggregate function to the ones
+// matching the conditions: COUNT(*) FILTER (WHERE x)
+// Several clauses are combined with AND
+// NOTE: mysql does not support it
+func (c AggregateClause) Filter(clauses ...Clause) AggregateClause {
+	filter := Where(clauses...)
+	c.filter = &filter
+	return c
+}
+
+// OrderBy sets the order of the aggregated values, rendered inside the
+// function parentheses: ARRAY_AGG(x ORDER BY y)
+// Like the statement ORDER BY, it accepts Asc() and Desc() items to set the
+// direction and the nulls ordering of each column:
+// ARRAY_AGG(x ORDER BY y DESC NULLS LAST)
+func (c AggregateClause) OrderBy(columns ...Clause) AggregateClause {
+	c.orderBy = &OrderByClause{columns, "ASC"}
+	return c
+}
+
+// Asc sets the direction of the aggregate order by
+// NOTE: Please use it after calling OrderBy()
+func (c AggregateClause) Asc() AggregateClause {
+	orderBy := *c.orderBy
+	orderBy.t = "ASC"
+	c.orderBy = &orderBy
+	return c
+}
+
+// Desc sets the direction of the aggregate order by
+// NOTE: Please use it after calling OrderBy()
+func (c AggregateClause) Desc() AggregateClause {
+	orderBy := *c.orderBy
+	orderBy.t = "DESC"
+	c.orderBy = &orderBy
+	return c
+}
+
+// Over makes the aggregate a window function call, to be completed with the
+// window partition, order and frame:
+// CountStar().Over().OrderBy(orders.C("id"))
+func (c AggregateClause) Over() WindowClause {
+	return Over(c)
 }
 
 // Accept calls the compiler VisitAggregate function
@@ -13,3 +13,117 @@ func TestAggregates(t *testing.T) {
 	assert.Equal(t, Aggregate("MIN", col), Min(col))
 	assert.Equal(t, Aggregate("MAX", col), Max(col))
 }
+
+func TestCountDistinct(t *testing.T) {
+	col := Column("id", Varchar().Size(36))
+	assert.Equal(t, "COUNT(DISTINCT id)", asDefSQL(CountDistinct(col)))
+	assert.Equal(t, "COUNT(id)", asDefSQL(Count(col)))
+}
+
+func TestAggregateOrderBy(t *testing.T) {
+	users := Table(
+		"users",
+		Column("name", Varchar()),
+		Column("age", Int()),
+	)
+	postgres := NewDialect("postgres")
+	postgres.SetEscaping(true)
+
+	agg := ArrayAgg(users.C("name")).OrderBy(users.C("age"))
+	assert.Equal(t, "ARRAY_AGG(users.name ORDER BY users.age ASC)", asSQL(agg, NewDialect("postgres")))
+	assert.Equal(t, `ARRAY_AGG("users"."name" ORDER BY "users"."age" DESC)`, asSQL(agg.Desc(), postgres))
+	// Desc returns a copy
+	assert.Equal(t, "ARRAY_AGG(users.name ORDER BY users.age ASC)", asSQL(agg, NewDialect("postgres")))
+
+	statement := Select(ArrayAgg(users.C("name")).OrderBy(users.C("age")).Desc()).From(users).Build(postgres)
+	assert.Equal(t, "SELECT ARRAY_AGG(\"name\" ORDER BY \"age\" DESC)\nFROM \"users\";", statement.SQL())
+}
+
+func TestAggregateFilter(t *testing.T) {
+	tasks := Table(
+		"tasks",
+		Column("id", Int()),
+		Column("status", Varchar()),
+		Column("priority", Int()),
+	)
+
+	sel := Select(
+		As(CountStar().Filter(tasks.C("status").Eq("done")), "done"),
+		As(CountStar().Filter(tasks.C("status").Eq("open"), tasks.C("priority").Gt(2)), "urgent"),
+	).From(tasks)
+
+	sql, binds := asSQLBinds(sel, NewDialect("postgres"))
+	assert.Equal(t, "SELECT COUNT(*) FILTER (WHERE status = $1) AS done, "+
+		"COUNT(*) FILTER (WHERE (status = $2 AND priority > $3)) AS urgent\n"+
+		"FROM tasks", sql)
+	assert.Equal(t, []interface{}{"done", "open", 2}, binds)
+
+	sql, _ = asSQLBinds(sel, NewDialect("sqlite3"))
+	assert.Contains(t, sql, "COUNT(*) FILTER (WHERE status = ?) AS done")
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("mysql")), sel)
+	assert.EqualError(t, err, "FILTER is not supported by mysql")
+}
+
+func TestAggregateOrderByNulls(t *testing.T) {
+	users := Table(
+		"users",
+		Column("name", Varchar()),
+		Column("age", Int()),
+		Column("score", Int()),
+	)
+
+	agg := ArrayAgg(users.C("name")).OrderBy(Desc(users.C("age")).NullsLast())
+	assert.Equal(t, "ARRAY_AGG(users.name ORDER BY users.age DESC NULLS LAST)", asSQL(agg, NewDialect("postgres")))
+
+	agg = ArrayAgg(users.C("name")).OrderBy(Asc(users.C("score")).NullsFirst(), Desc(users.C("age")).NullsLast())
+	statement := Select(agg).From(users).Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT ARRAY_AGG(name ORDER BY score ASC NULLS FIRST, age DESC NULLS LAST)\nFROM users;", statement.SQL())
+}
+
+func TestAggregateDistinct(t *testing.T) {
+	orders := Table(
+		"orders",
+		Column("id", Int()),
+		Column("user_id", Int()),
+		Column("shop_id", Int()),
+		Column("amount", Int()),
+	)
+
+	assert.Equal(t, CountDistinct(orders.C("user_id")), Count(orders.C("user_id")).Distinct())
+	assert.Equal(t, "SUM(DISTINCT orders.amount)", asDefSQL(Sum(orders.C("amount")).Distinct()))
+
+	statement := Select(orders.C("shop_id"), CountDistinct(orders.C("user_id"))).
+		From(orders).
+		GroupBy(orders.C("shop_id")).
+		Having(CountDistinct(orders.C("user_id")), ">", 10).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT shop_id, COUNT(DISTINCT user_id)\n"+
+		"FROM orders\n"+
+		"GROUP BY shop_id\n"+
+		"HAVING COUNT(DISTINCT user_id) > $1;", statement.SQL())
+	assert.Equal(t, []interface{}{10}, statement.Bindings())
+}
+
+func TestStringAggDistinct(t *testing.T) {
+	tags := Table("tags", Column("post_id", Int()), Column("name", Varchar()))
+
+	assert.Equal(t, "STRING_AGG(tags.name, ', ')", asDefSQL(StringAgg(tags.C("name"), ", ")))
+	assert.Equal(t, "STRING_AGG(DISTINCT tags.name, ',')", asDefSQL(StringAgg(tags.C("name"), ",").Distinct()))
+
+	statement := Select(tags.C("post_id"), StringAgg(tags.C("name"), ",").Distinct().OrderBy(Desc(tags.C("name")))).
+		From(tags).
+		GroupBy(tags.C("post_id")).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT post_id, STRING_AGG(DISTINCT name, ',' ORDER BY name DESC)\n"+
+		"FROM tags\n"+
+		"GROUP BY post_id;", statement.SQL())
+
+	assert.Equal(t, "ARRAY_AGG(DISTINCT tags.name ORDER BY tags.name ASC)",
+		asDefSQL(ArrayAgg(tags.C("name")).Distinct().OrderBy(tags.C("name"))))
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.Strict = true
+	_, err := BuildStmt(context, Select(StringAgg(tags.C("name"), ",").Distinct().OrderBy(tags.C("post_id"))).From(tags))
+	assert.EqualError(t, err, "ORDER BY expression post_id must be the aggregated expression of a STRING_AGG(DISTINCT ...)")
+}
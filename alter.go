@@ -0,0 +1,70 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlterTable generates an alter table statement and returns it for chaining
+// AlterTable(sessions).AddForeignKey("fk_user", ForeignKey("user_id").References("users", "id"))
+func AlterTable(table TableElem) AlterTableStmt {
+	return AlterTableStmt{table: table}
+}
+
+// AlterTableStmt is the base struct for building alter table statements
+type AlterTableStmt struct {
+	table       TableElem
+	foreignKeys []namedForeignKey
+}
+
+// namedForeignKey is a foreign key added by an alter table statement, with
+// its optional constraint name
+type namedForeignKey struct {
+	name string
+	fkey ForeignKeyConstraint
+}
+
+// AddForeignKey adds a foreign key constraint to the table, with its ON
+// UPDATE and ON DELETE actions. The constraint is named after name, unless
+// it is empty
+// NOTE: sqlite does not support it
+func (s AlterTableStmt) AddForeignKey(name string, fkey ForeignKeyConstraint) AlterTableStmt {
+	s.foreignKeys = append(append([]namedForeignKey{}, s.foreignKeys...), namedForeignKey{name, fkey})
+	return s
+}
+
+// SQL returns the alter table syntax for the given dialect
+// It panics if the dialect does not support it
+func (s AlterTableStmt) SQL(dialect Dialect) string {
+	if !dialect.SupportsAlterForeignKey() {
+		panic("ALTER TABLE ADD FOREIGN KEY is not supported by this dialect")
+	}
+	if len(s.foreignKeys) == 0 {
+		panic(fmt.Sprintf("Alter table %s has nothing to alter", s.table.Name))
+	}
+
+	actions := []string{}
+	for _, fk := range s.foreignKeys {
+		action := "ADD "
+		if fk.name != "" {
+			action += "CONSTRAINT " + dialect.Escape(fk.name) + " "
+		}
+		actions = append(actions, action+fk.fkey.definition(dialect))
+	}
+
+	statement := Statement()
+	statement.AddSQLClause("ALTER TABLE " + dialect.Escape(s.table.Name))
+	statement.AddSQLClause(strings.Join(actions, ",\n"))
+	return statement.SQL()
+}
+
+// String returns the alter table syntax for the default dialect, for
+// inspection
+func (s AlterTableStmt) String() (sql string) {
+	defer func() {
+		if r := recover(); r != nil {
+			sql = fmt.Sprintf("<invalid statement: %v>", r)
+		}
+	}()
+	return s.SQL(NewDialect("default"))
+}
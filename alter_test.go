@@ -0,0 +1,34 @@
+package qb
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAlterTableAddForeignKey(t *testing.T) {
+	sessions := Table("sessions", Column("id", Int()), Column("user_id", Int()), Column("device_id", Int()))
+
+	alter := AlterTable(sessions).
+		AddForeignKey("fk_sessions_user", ForeignKey("user_id").References("users", "id").OnDelete("set null"))
+
+	assert.Equal(t,
+		"ALTER TABLE sessions\nADD CONSTRAINT fk_sessions_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE SET NULL;",
+		alter.SQL(NewDialect("postgres")))
+	assert.Equal(t,
+		"ALTER TABLE sessions\nADD CONSTRAINT fk_sessions_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE SET NULL;",
+		fmt.Sprint(alter))
+
+	mysql := NewDialect("mysql")
+	mysql.SetEscaping(true)
+	assert.Equal(t,
+		"ALTER TABLE `sessions`\n"+
+			"ADD CONSTRAINT `fk_sessions_user` FOREIGN KEY(`user_id`) REFERENCES `users`(`id`) ON DELETE SET NULL,\n"+
+			"ADD FOREIGN KEY(`device_id`) REFERENCES `devices`(`id`) ON UPDATE CASCADE ON DELETE RESTRICT;",
+		alter.AddForeignKey("", ForeignKey("device_id").References("devices", "id").OnUpdate("cascade").OnDelete("restrict")).SQL(mysql))
+
+	assert.Panics(t, func() { alter.SQL(NewDialect("sqlite3")) })
+	assert.Panics(t, func() { AlterTable(sessions).SQL(NewDialect("postgres")) })
+
+	assert.Equal(t, "<invalid statement: Alter table sessions has nothing to alter>", AlterTable(sessions).String())
+}
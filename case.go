@@ -0,0 +1,49 @@
+package qb
+
+// Case generates a searched CASE expression, whose When() branches are
+// conditions:
+// Case().When(tasks.C("status").Eq("done"), 1).Else(0)
+func Case() CaseClause {
+	return CaseClause{}
+}
+
+// CaseExpr generates a simple CASE expression, that compares expr to the
+// values of its When() branches:
+// CaseExpr(tasks.C("status")).When("urgent", 0).When("high", 1).Else(2)
+func CaseExpr(expr Clause) CaseClause {
+	return CaseClause{expr: expr}
+}
+
+// CaseClause is a CASE ... WHEN ... THEN ... ELSE ... END expression
+type CaseClause struct {
+	expr       Clause
+	whens      []WhenClause
+	elseResult Clause
+}
+
+// WhenClause is a WHEN ... THEN ... branch of a CASE expression
+type WhenClause struct {
+	Condition Clause
+	Result    Clause
+}
+
+// When appends a WHEN ... THEN ... branch to the CASE expression. The
+// condition and result values that are not clauses are bound
+func (c CaseClause) When(condition interface{}, result interface{}) CaseClause {
+	c.whens = append(append([]WhenClause{}, c.whens...), WhenClause{
+		Condition: GetClauseFrom(condition),
+		Result:    GetClauseFrom(result),
+	})
+	return c
+}
+
+// Else sets the result of the CASE expression when no branch matches
+func (c CaseClause) Else(result interface{}) CaseClause {
+	c.elseResult = GetClauseFrom(result)
+	return c
+}
+
+// Accept calls the compiler VisitCase function
+func (c CaseClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitCase(context, c)
+}
@@ -0,0 +1,66 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCaseExpr(t *testing.T) {
+	tasks := Table("tasks", Column("id", Int()), Column("status", Varchar()))
+	priority := CaseExpr(tasks.C("status")).When("urgent", 0).When("high", 1).Else(2)
+
+	sql, binds := asDefSQLBinds(priority)
+	assert.Equal(t, "CASE tasks.status WHEN ? THEN ? WHEN ? THEN ? ELSE ? END", sql)
+	assert.Equal(t, []interface{}{"urgent", 0, "high", 1, 2}, binds)
+
+	statement := Select(tasks.C("id")).
+		From(tasks).
+		Where(tasks.C("id").Gt(10)).
+		OrderBy(priority, tasks.C("id")).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM tasks\nWHERE id > $1\nORDER BY CASE status WHEN $2 THEN $3 WHEN $4 THEN $5 ELSE $6 END, id ASC;", statement.SQL())
+	assert.Equal(t, []interface{}{10, "urgent", 0, "high", 1, 2}, statement.Bindings())
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("default")), Select(CaseExpr(tasks.C("status"))))
+	assert.EqualError(t, err, "CASE has no WHEN branch")
+}
+
+func TestCase(t *testing.T) {
+	tasks := Table("tasks", Column("id", Int()), Column("status", Varchar()), Column("due", Int()))
+	state := Case().
+		When(tasks.C("status").Eq("done"), "closed").
+		When(tasks.C("due").Lt(10), "late").
+		Else("pending")
+
+	sql, binds := asDefSQLBinds(state)
+	assert.Equal(t, "CASE WHEN tasks.status = ? THEN ? WHEN tasks.due < ? THEN ? ELSE ? END", sql)
+	assert.Equal(t, []interface{}{"done", "closed", 10, "late", "pending"}, binds)
+
+	statement := Select(tasks.C("id"), As(state, "state")).
+		From(tasks).
+		Where(Gt(Case().When(tasks.C("status").Eq("done"), tasks.C("due")).Else(0), 5)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id, CASE WHEN status = $1 THEN $2 WHEN due < $3 THEN $4 ELSE $5 END AS state\n"+
+		"FROM tasks\n"+
+		"WHERE CASE WHEN status = $6 THEN due ELSE $7 END > $8;", statement.SQL())
+	assert.Equal(t, []interface{}{"done", "closed", 10, "late", "pending", "done", 0, 5}, statement.Bindings())
+}
+
+func TestCaseExprClauses(t *testing.T) {
+	tasks := Table("tasks", Column("id", Int()), Column("status", Varchar()), Column("points", Int()))
+	rank := CaseExpr(tasks.C("status")).
+		When(StringLiteral("a"), IntLiteral(1)).
+		When(StringLiteral("b"), tasks.C("points")).
+		Else(Bind(0))
+
+	assert.Equal(t, "CASE tasks.status WHEN 'a' THEN 1 WHEN 'b' THEN tasks.points ELSE ? END", asDefSQL(rank))
+
+	statement := Select(tasks.C("id"), As(rank, "rank")).
+		From(tasks).
+		Where(Gt(CaseExpr(tasks.C("status")).When("c", tasks.C("points")).Else(10), 3)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id, CASE status WHEN 'a' THEN 1 WHEN 'b' THEN points ELSE $1 END AS rank\n"+
+		"FROM tasks\n"+
+		"WHERE CASE status WHEN $2 THEN points ELSE $3 END > $4;", statement.SQL())
+	assert.Equal(t, []interface{}{0, "c", 10, 3}, statement.Bindings())
+}
@@ -1,13 +1,27 @@
 package qb
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
 // SQLText returns a raw SQL clause
 func SQLText(text string) TextClause {
 	return TextClause{Text: text}
 }
 
+// SQLTextBinds returns a raw SQL clause with bound values. Each '?' of the
+// text is a placeholder, that is rendered as the dialect one
+// SQLTextBinds("jsonb_path_exists(data, ?)", path)
+func SQLTextBinds(text string, binds ...interface{}) TextClause {
+	return TextClause{Text: text, Binds: binds}
+}
+
 // TextClause is a raw SQL clause
 type TextClause struct {
-	Text string
+	Text  string
+	Binds []interface{}
 }
 
 // Accept calls the compiler VisitText method
@@ -15,6 +29,56 @@ func (c TextClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitText(context, c)
 }
 
+// EmbedClause is a previously built statement spliced into another one, see
+// Stmt.Embed(). Its SQL is cut at its placeholders: Binds[i] is bound
+// between Parts[i] and Parts[i+1]
+type EmbedClause struct {
+	Parts []string
+	Binds []interface{}
+}
+
+// Accept calls the compiler VisitEmbed method
+func (c EmbedClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitEmbed(context, c)
+}
+
+// StringLiteral returns a string literal clause, to inline a string value
+// where a bind is not possible
+func StringLiteral(value string) StringLiteralClause {
+	return StringLiteralClause{Value: value}
+}
+
+// StringLiteralClause is a single-quoted SQL string
+type StringLiteralClause struct {
+	Value string
+}
+
+// Accept calls the compiler VisitStringLiteral method
+func (c StringLiteralClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitStringLiteral(context, c)
+}
+
+// IntLiteral returns a raw SQL clause of an integer, to inline it where a
+// bind is not possible
+func IntLiteral(value int64) TextClause {
+	return SQLText(strconv.FormatInt(value, 10))
+}
+
+// FloatLiteral returns a raw SQL clause of a float, to inline it where a
+// bind is not possible. The formatting does not depend on the locale.
+// It panics if value is NaN or infinite, which have no SQL literal
+func FloatLiteral(value float64) TextClause {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		panic(fmt.Sprintf("Invalid float literal: %v", value))
+	}
+	return SQLText(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// NullLiteral returns a raw SQL NULL clause
+func NullLiteral() TextClause {
+	return SQLText("NULL")
+}
+
 // List returns a list-of-clauses clause
 func List(clauses ...Clause) ListClause {
 	return ListClause{
@@ -49,9 +113,31 @@ func (c BindClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitBind(context, c)
 }
 
+// BindName returns a bind whose value is looked up by name, when the
+// statement is compiled, in the parameters given to BuildNamed() or
+// CompilerContext.SetParams(). It is compiled as a regular bind, each
+// reference to the name adding its value to the binds
+func BindName(name string) NamedBindClause {
+	return NamedBindClause{Name: name}
+}
+
+// NamedBindClause binds a named parameter to a placeholder
+type NamedBindClause struct {
+	Name string
+}
+
+// Accept calls the compiler VisitNamedBind method
+func (c NamedBindClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitNamedBind(context, c)
+}
+
 // GetClauseFrom returns the value if already a Clause, or make one
-// if it is a scalar value
+// if it is a scalar value. A SelectStmt is wrapped in a Subquery
 func GetClauseFrom(value interface{}) Clause {
+	// A select statement used as a value is a scalar subquery
+	if sel, ok := value.(SelectStmt); ok {
+		return Subquery(sel)
+	}
 	if clause, ok := value.(Clause); ok {
 		return clause
 	}
@@ -70,6 +156,10 @@ func GetListFrom(values ...interface{}) Clause {
 		if clause, ok := values[0].(ListClause); ok {
 			return clause
 		}
+		// The list parentheses are the subquery ones: IN (SELECT ...)
+		if sel, ok := values[0].(SelectStmt); ok {
+			return List(sel)
+		}
 	}
 
 	var clauses []Clause
@@ -105,3 +195,128 @@ type ExistsClause struct {
 func (c ExistsClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitExists(context, c)
 }
+
+// Subquery returns a SubqueryClause
+func Subquery(sel SelectStmt) SubqueryClause {
+	return SubqueryClause{
+		Select: sel,
+	}
+}
+
+// SubqueryClause is a parenthesized select statement that can be used as an
+// expression, typically a scalar subquery.
+// It is also a Selectable, that must be aliased to be used in a FROM clause:
+// From(Alias("sub", Subquery(sel)))
+type SubqueryClause struct {
+	Select SelectStmt
+}
+
+// Accept calls compiler VisitSubquery method
+func (c SubqueryClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitSubquery(context, c)
+}
+
+// All returns the columns of the subquery
+func (c SubqueryClause) All() []Clause {
+	var clauses []Clause
+	for _, col := range c.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the named columns selected by the subquery, with no
+// table: an alias of the subquery sets it
+func (c SubqueryClause) ColumnList() []ColumnElem {
+	var cols []ColumnElem
+	for i, name := range c.Select.ColumnNames() {
+		if name == "" {
+			continue
+		}
+		col := ColumnElem{Name: name}
+		if selCol, ok := c.Select.sel[i].(ColumnElem); ok {
+			col = selCol
+		}
+		col.Table = ""
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// C returns the subquery column with the given name
+func (c SubqueryClause) C(name string) ColumnElem {
+	for _, col := range c.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	panic(fmt.Sprintf("No such column '%s' in subquery", name))
+}
+
+// DefaultName returns an empty name, a subquery has no name unless aliased
+func (c SubqueryClause) DefaultName() string {
+	return ""
+}
+
+// Values returns a ValuesClause of the given rows, each row giving a value
+// for each of the columns. A value can be a Clause, any other value is bound
+func Values(columns []string, rows ...[]interface{}) ValuesClause {
+	values := ValuesClause{Columns: columns}
+	for _, row := range rows {
+		clauses := []Clause{}
+		for _, value := range row {
+			clauses = append(clauses, GetClauseFrom(value))
+		}
+		values.Rows = append(values.Rows, clauses)
+	}
+	return values
+}
+
+// ValuesClause is a parenthesized '(VALUES (...), (...))' list of rows.
+// It is a Selectable, that must be aliased to be used in a FROM clause, the
+// alias then naming its columns: From(Alias("d", Values(...))) compiles to
+// (VALUES (...), (...)) AS d(col1, col2)
+// NOTE: sqlite does not support it
+type ValuesClause struct {
+	Columns []string
+	Rows    [][]Clause
+}
+
+// Accept calls compiler VisitValues method
+func (c ValuesClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitValues(context, c)
+}
+
+// All returns the columns of the values list
+func (c ValuesClause) All() []Clause {
+	var clauses []Clause
+	for _, col := range c.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the columns of the values list, with no table: an alias
+// of the values list sets it
+func (c ValuesClause) ColumnList() []ColumnElem {
+	var cols []ColumnElem
+	for _, name := range c.Columns {
+		cols = append(cols, ColumnElem{Name: name})
+	}
+	return cols
+}
+
+// C returns the values list column with the given name
+func (c ValuesClause) C(name string) ColumnElem {
+	for _, col := range c.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	panic(fmt.Sprintf("No such column '%s' in values list", name))
+}
+
+// DefaultName returns an empty name, a values list has no name unless aliased
+func (c ValuesClause) DefaultName() string {
+	return ""
+}
@@ -2,6 +2,7 @@ package qb
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -10,6 +11,25 @@ func TestSQLText(t *testing.T) {
 	assert.Equal(t, "1", text.Text)
 }
 
+func TestStringLiteral(t *testing.T) {
+	literal := StringLiteral(`it's a \ test`)
+	assert.Equal(t, `'it''s a \ test'`, asDefSQL(literal))
+	assert.Equal(t, `'it''s a \\ test'`, asSQL(literal, NewDialect("mysql")))
+	assert.Equal(t, `E'it''s a \\ test'`, asSQL(literal, NewDialect("postgres")))
+	assert.Equal(t, `DEFAULT 'it''s'`, Default("it's").String())
+}
+
+func TestNumericLiterals(t *testing.T) {
+	assert.Equal(t, "42", asDefSQL(IntLiteral(42)))
+	assert.Equal(t, "-7", asDefSQL(IntLiteral(-7)))
+	assert.Equal(t, "3.25", asDefSQL(FloatLiteral(3.25)))
+	assert.Equal(t, "-0.001", asDefSQL(FloatLiteral(-0.001)))
+	assert.Equal(t, "10000000000", asDefSQL(FloatLiteral(1e10)))
+	assert.Equal(t, "2", asDefSQL(FloatLiteral(2)))
+	assert.Panics(t, func() { FloatLiteral(math.NaN()) })
+	assert.Panics(t, func() { FloatLiteral(math.Inf(1)) })
+}
+
 func TestGetClauseFrom(t *testing.T) {
 	var c Clause
 	c = SQLText("1")
@@ -58,3 +78,43 @@ func TestExists(t *testing.T) {
 	assert.True(t, ne.Not)
 	assert.Equal(t, s, ne.Select)
 }
+
+func TestSubquery(t *testing.T) {
+	s := Select(SQLText("1"))
+
+	sub := Subquery(s)
+	assert.Equal(t, s, sub.Select)
+	assert.Equal(t, "(SELECT 1)", asDefSQL(sub))
+}
+
+func TestBindName(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("manager_id", Int()),
+		Column("created_by", Int()),
+	)
+
+	sel := Select(users.C("email")).
+		From(users).
+		Where(Or(
+			Eq(users.C("manager_id"), BindName("owner")),
+			Eq(users.C("created_by"), BindName("owner")),
+		), Like(users.C("email"), BindName("pattern")))
+
+	statement := sel.BuildNamed(NewDialect("postgres"), map[string]interface{}{"owner": 5, "pattern": "%@example.com"})
+	assert.Equal(t, "SELECT email\nFROM users\n"+
+		"WHERE ((manager_id = $1 OR created_by = $2) AND email LIKE $3);", statement.SQL())
+	assert.Equal(t, []interface{}{5, 5, "%@example.com"}, statement.Bindings())
+
+	statement = sel.BuildNamed(NewDialect("mysql"), map[string]interface{}{"owner": 7, "pattern": "joe%"})
+	assert.Equal(t, []interface{}{7, 7, "joe%"}, statement.Bindings())
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.SetParams(map[string]interface{}{"owner": nil})
+	_, err := BuildStmt(context, sel)
+	assert.EqualError(t, err, "Missing value for the named bind pattern")
+
+	assert.Panics(t, func() { sel.Build(NewDialect("postgres")) })
+}
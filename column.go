@@ -14,11 +14,18 @@ func Column(name string, t TypeElem) ColumnElem {
 }
 
 // ColumnOptions holds options for a column
+// Nullable, Default and Comment describe the column definition. They are set
+// by the Null, NotNull, Default and Comment setters, and may be filled
+// directly, for example from an introspected schema. Default is the SQL
+// expression of the default value
 type ColumnOptions struct {
 	AutoIncrement    bool
 	PrimaryKey       bool
 	InlinePrimaryKey bool
 	Unique           bool
+	Nullable         *bool
+	Default          *string
+	Comment          string
 }
 
 // ColumnElem is the definition of any columns defined in a table
@@ -60,7 +67,17 @@ func (c ColumnElem) String(dialect Dialect) string {
 		colSpec = dialect.CompileType(c.Type)
 		constraintNames := []string{}
 		for _, constraint := range c.Constraints {
-			constraintNames = append(constraintNames, constraint.String())
+			constraintNames = append(constraintNames, constraint.sql(dialect))
+		}
+		if c.Options.Nullable != nil && !c.hasConstraint("NULL", "NOT NULL") {
+			if *c.Options.Nullable {
+				constraintNames = append(constraintNames, Null().String())
+			} else {
+				constraintNames = append(constraintNames, NotNull().String())
+			}
+		}
+		if c.Options.Default != nil && !c.hasConstraint("DEFAULT "+*c.Options.Default) {
+			constraintNames = append(constraintNames, "DEFAULT "+*c.Options.Default)
 		}
 		if len(constraintNames) != 0 {
 			colSpec = fmt.Sprintf("%s %s", colSpec, strings.Join(constraintNames, " "))
@@ -69,10 +86,25 @@ func (c ColumnElem) String(dialect Dialect) string {
 			colSpec += " PRIMARY KEY"
 		}
 	}
+	if c.Options.Comment != "" && dialect.SupportsInlineComments() {
+		colSpec += " COMMENT " + dialect.QuoteLiteral(c.Options.Comment)
+	}
 	res := fmt.Sprintf("%s %s", dialect.Escape(c.Name), colSpec)
 	return res
 }
 
+// hasConstraint returns true if the column has one of the given constraints
+func (c ColumnElem) hasConstraint(names ...string) bool {
+	for _, constraint := range c.Constraints {
+		for _, name := range names {
+			if constraint.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Accept calls the compiler VisitColumn function
 func (c ColumnElem) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitColumn(context, c)
@@ -82,19 +114,34 @@ func (c ColumnElem) Accept(context *CompilerContext) string {
 
 // Default adds a default constraint to column type
 func (c ColumnElem) Default(def interface{}) ColumnElem {
-	c.Constraints = append(c.Constraints, Default(def))
+	constraint := Default(def)
+	value := strings.TrimPrefix(constraint.Name, "DEFAULT ")
+	c.Constraints = append(c.Constraints, constraint)
+	c.Options.Default = &value
 	return c
 }
 
 // Null adds null constraint to column type
 func (c ColumnElem) Null() ColumnElem {
+	nullable := true
 	c.Constraints = append(c.Constraints, Null())
+	c.Options.Nullable = &nullable
 	return c
 }
 
 // NotNull adds not null constraint to column type
 func (c ColumnElem) NotNull() ColumnElem {
+	nullable := false
 	c.Constraints = append(c.Constraints, NotNull())
+	c.Options.Nullable = &nullable
+	return c
+}
+
+// Comment sets the comment of the column. mysql renders it in the column
+// definition, postgres in a COMMENT ON COLUMN statement following the
+// CREATE TABLE, sqlite ignores it
+func (c ColumnElem) Comment(comment string) ColumnElem {
+	c.Options.Comment = comment
 	return c
 }
 
@@ -118,11 +165,26 @@ func (c ColumnElem) Like(pattern string) Clause {
 	return Like(c, pattern)
 }
 
+// LikeAny wraps the LikeAny(col ColumnElem, patterns ...string)
+func (c ColumnElem) LikeAny(patterns ...string) Clause {
+	return LikeAny(c, patterns...)
+}
+
+// ILikeAny wraps the ILikeAny(col ColumnElem, patterns ...string)
+func (c ColumnElem) ILikeAny(patterns ...string) Clause {
+	return ILikeAny(c, patterns...)
+}
+
 // NotIn wraps the NotIn(col ColumnElem, values ...interface{})
 func (c ColumnElem) NotIn(values ...interface{}) Clause {
 	return NotIn(c, values...)
 }
 
+// InTopN wraps the InTopN(col ColumnElem, sel SelectStmt)
+func (c ColumnElem) InTopN(sel SelectStmt) Clause {
+	return InTopN(c, sel)
+}
+
 // In wraps the In(col ColumnElem, values ...interface{})
 func (c ColumnElem) In(values ...interface{}) Clause {
 	return In(c, values...)
@@ -138,6 +200,26 @@ func (c ColumnElem) Eq(value interface{}) Clause {
 	return Eq(c, value)
 }
 
+// IsDistinctFrom wraps the IsDistinctFrom(col ColumnElem, value interface{})
+func (c ColumnElem) IsDistinctFrom(value interface{}) Clause {
+	return IsDistinctFrom(c, value)
+}
+
+// IsNotDistinctFrom wraps the IsNotDistinctFrom(col ColumnElem, value interface{})
+func (c ColumnElem) IsNotDistinctFrom(value interface{}) Clause {
+	return IsNotDistinctFrom(c, value)
+}
+
+// Between wraps the Between(col ColumnElem, lower, upper interface{})
+func (c ColumnElem) Between(lower interface{}, upper interface{}) Clause {
+	return Between(c, lower, upper)
+}
+
+// NotBetween wraps the NotBetween(col ColumnElem, lower, upper interface{})
+func (c ColumnElem) NotBetween(lower interface{}, upper interface{}) Clause {
+	return NotBetween(c, lower, upper)
+}
+
 // Gt wraps the Gt(col ColumnElem, value interface{})
 func (c ColumnElem) Gt(value interface{}) Clause {
 	return Gt(c, value)
@@ -26,6 +26,16 @@ func TestColumn(t *testing.T) {
 	col = Column("s", Varchar().Size(255)).Unique().NotNull().Default("hello")
 	assert.Equal(t, "s VARCHAR(255) UNIQUE NOT NULL DEFAULT 'hello'", col.String(sqlite))
 
+	col = Column("path", Varchar().Size(255)).Default(`C:\tmp`)
+	assert.Equal(t, "`path` VARCHAR(255) DEFAULT 'C:\\\\tmp'", col.String(mysql))
+	assert.Equal(t, "\"path\" VARCHAR(255) DEFAULT E'C:\\\\tmp'", col.String(postgres))
+	assert.Equal(t, "path VARCHAR(255) DEFAULT 'C:\\tmp'", col.String(sqlite))
+
+	col = Column("active", Boolean()).Default(true)
+	assert.Equal(t, "active BOOLEAN DEFAULT TRUE", col.String(sqlite))
+	col = Column("score", Int()).Default(0)
+	assert.Equal(t, "score INT DEFAULT 0", col.String(sqlite))
+
 	precisionCol := Column("f", Type("FLOAT").Precision(2, 5)).Null()
 	assert.Equal(t, "f FLOAT(2, 5) NULL", precisionCol.String(sqlite))
 
@@ -1,22 +1,103 @@
 package qb
 
+import "errors"
+
 // And generates an AndClause given conditional clauses
 func And(clauses ...Clause) CombinerClause {
-	return CombinerClause{"AND", clauses}
+	return CombinerClause{operator: "AND", clauses: clauses}
 }
 
 // Or generates an AndClause given conditional clauses
 func Or(clauses ...Clause) CombinerClause {
-	return CombinerClause{"OR", clauses}
+	return CombinerClause{operator: "OR", clauses: clauses}
 }
 
 // CombinerClause is for OR and AND clauses
 type CombinerClause struct {
 	operator string
 	clauses  []Clause
+	nullAs   *bool
+}
+
+// NullAs makes the combined condition evaluate to the given value instead
+// of NULL, following the three-valued logic, when its result is unknown:
+// COALESCE((a AND b), FALSE)
+func (c CombinerClause) NullAs(value bool) CombinerClause {
+	c.nullAs = &value
+	return c
 }
 
 // Accept calls the compiler VisitCombiner entry point
 func (c CombinerClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitCombiner(context, c)
 }
+
+// Group generates a parenthesized clause, to control the precedence of raw
+// SQL conditions. And() and Or() are always parenthesized, and are not
+// wrapped again
+func Group(clause Clause) GroupClause {
+	return GroupClause{clause}
+}
+
+// GroupClause is a parenthesized clause
+type GroupClause struct {
+	clause Clause
+}
+
+// Accept calls the compiler VisitGroup entry point
+func (c GroupClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitGroup(context, c)
+}
+
+// Conditions accumulates conditions, so several functions can add theirs to
+// the same WHERE clause:
+// var conditions Conditions
+// conditions.Add(users.C("active").Eq(true))
+// conditions.AddOr(users.C("role").Eq("admin"))
+// Select(...).From(users).Where(conditions.Clause())
+// Contrary to the clauses, it is mutable: it is meant to be passed by
+// pointer, and Clause() returns the conditions accumulated so far
+type Conditions struct {
+	clauses []Clause
+}
+
+// Add combines the accumulated conditions and the given ones with AND
+func (c *Conditions) Add(clauses ...Clause) *Conditions {
+	c.clauses = append(c.clauses, clauses...)
+	return c
+}
+
+// AddOr combines the accumulated conditions and the given ones with OR
+func (c *Conditions) AddOr(clauses ...Clause) *Conditions {
+	if len(c.clauses) == 0 {
+		c.clauses = []Clause{Or(clauses...)}
+		return c
+	}
+	c.clauses = []Clause{Or(append([]Clause{c.Clause()}, clauses...)...)}
+	return c
+}
+
+// Len returns the number of the conditions combined at the top level, 0 if
+// none was added
+func (c *Conditions) Len() int {
+	return len(c.clauses)
+}
+
+// Clause returns the accumulated conditions as a single clause, which later
+// additions do not change. Check Len() first if all the conditions are
+// optional, an empty And() is not a valid condition
+func (c *Conditions) Clause() Clause {
+	if len(c.clauses) == 1 {
+		return c.clauses[0]
+	}
+	return And(append([]Clause{}, c.clauses...)...)
+}
+
+// Accept compiles the accumulated conditions. Having none is an error
+func (c *Conditions) Accept(context *CompilerContext) string {
+	if len(c.clauses) == 0 {
+		context.AddError(errors.New("Conditions has no condition to compile"))
+		return ""
+	}
+	return c.Clause().Accept(context)
+}
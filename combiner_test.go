@@ -58,3 +58,80 @@ func TestCombiners(t *testing.T) {
 	assert.Equal(t, "(\"email\" = $1 OR \"id\" != $2)", sql)
 	assert.Equal(t, []interface{}{"al@pacino.com", 1}, ctx.Binds)
 }
+
+func TestGroup(t *testing.T) {
+	a, b, c := SQLText("a"), SQLText("b"), SQLText("c")
+
+	assert.Equal(t, "(a)", asDefSQL(Group(a)))
+	assert.Equal(t, "(a OR b)", asDefSQL(Group(Or(a, b))))
+
+	assert.Equal(t, "WHERE (a AND (b OR c))", asDefSQL(Where(a).And(Or(b, c))))
+	assert.Equal(t, "WHERE ((a AND b) OR c)", asDefSQL(Where(a).And(b).Or(c)))
+
+	assert.Equal(t, "WHERE (a AND b OR c)", asDefSQL(Where(a, SQLText("b OR c"))))
+	assert.Equal(t, "WHERE (a AND (b OR c))", asDefSQL(Where(a, Group(SQLText("b OR c")))))
+}
+
+func TestCombinerNullAs(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("score", Int()),
+	)
+
+	cond := And(Gt(users.C("score"), 10), Like(users.C("email"), "%@example.com"))
+	assert.Equal(t, "COALESCE((users.score > ? AND users.email LIKE ?), FALSE)", asDefSQL(cond.NullAs(false)))
+	assert.Equal(t, "COALESCE((users.score > ? OR users.email LIKE ?), TRUE)", asDefSQL(Or(Gt(users.C("score"), 10), Like(users.C("email"), "%@example.com")).NullAs(true)))
+	// NullAs returns a copy
+	assert.Equal(t, "(users.score > ? AND users.email LIKE ?)", asDefSQL(cond))
+
+	statement := Select(users.C("id")).
+		From(users).
+		Where(cond.NullAs(false)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE COALESCE((score > $1 AND email LIKE $2), FALSE);", statement.SQL())
+	assert.Equal(t, []interface{}{10, "%@example.com"}, statement.Bindings())
+}
+
+func TestConditions(t *testing.T) {
+	users := Table("users",
+		Column("id", Int()),
+		Column("active", Boolean()),
+		Column("role", Varchar()),
+		Column("email", Varchar()),
+	)
+
+	base := func(conditions *Conditions) {
+		conditions.Add(users.C("active").Eq(true))
+	}
+	optional := func(conditions *Conditions, role string, email string) {
+		if role != "" {
+			conditions.Add(users.C("role").Eq(role))
+		}
+		if email != "" {
+			conditions.AddOr(users.C("email").Eq(email))
+		}
+	}
+
+	var conditions Conditions
+	base(&conditions)
+	assert.Equal(t, 1, conditions.Len())
+	assert.Equal(t, "users.active = ?", asDefSQL(&conditions))
+
+	optional(&conditions, "admin", "")
+	sel := Select(users.C("id")).From(users).Where(conditions.Clause())
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE (active = $1 AND role = $2);", sel.Build(NewDialect("postgres")).SQL())
+
+	optional(&conditions, "", "al@pacino.com")
+	statement := Select(users.C("id")).From(users).Where(&conditions).Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE ((active = $1 AND role = $2) OR email = $3);", statement.SQL())
+	assert.Equal(t, []interface{}{true, "admin", "al@pacino.com"}, statement.Bindings())
+
+	// the clause taken before is not changed by the later additions
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE (active = $1 AND role = $2);", sel.Build(NewDialect("postgres")).SQL())
+
+	var none Conditions
+	_, err := BuildStmt(NewCompilerContext(NewDialect("postgres")), Select(users.C("id")).From(users).Where(&none))
+	assert.EqualError(t, err, "Conditions has no condition to compile")
+}
@@ -1,7 +1,12 @@
 package qb
 
 import (
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -19,39 +24,184 @@ func NewCompilerContext(dialect Dialect) *CompilerContext {
 // functions. It contains the bindings, links to the Dialect and Compiler
 // being used, and some contextual informations that can be used by the
 // compiler functions to communicate during the compilation.
+// The errors found by the compiler functions are accumulated in Errors.
+// If Strict is set, the compiler functions run extra checks to report
+// errors that the database would otherwise raise, and the identifiers that
+// are reserved words are quoted even if the dialect escaping is off.
+// If Safe is set, the DELETE and UPDATE statements that have no WHERE clause
+// are reported as errors, unless they are marked with Unsafe().
+// If CheckBinds is set, BuildStmt counts the placeholders of the SQL and
+// reports an error if they do not match the binds. It is meant for the
+// tests, and does not handle custom placeholders.
+// If FetchFirst is set, the select limits are rendered in the standard
+// OFFSET ... ROWS FETCH FIRST ... ROWS ONLY form, which postgres supports
+// but mysql and sqlite do not.
+// If NullComparisons is set, the = and != comparisons to a nil bind are
+// rendered as IS NULL and IS NOT NULL.
+// If ArrayIn is set, postgres compiles an IN of a single slice value as
+// = ANY($1), and a NOT IN as != ALL($1), with the slice as only bind. The
+// driver must accept it, wrap it with pq.Array() if needed: the pq.Array()
+// values are recognized as arrays.
+// If Placeholder is set, it renders the bind placeholders instead of the
+// dialect default. It is given the 1-based index of the bind.
+// If DedupBinds is set, the numbered placeholders are reused for the binds
+// of equal comparable values, so a value repeated across the statement, in
+// the members of a UNION for example, is bound once. It applies to postgres
+// and to the custom placeholders.
+// If CountExists is set, the comparisons of a COUNT(*) subquery to 0 are
+// rewritten as EXISTS and NOT EXISTS, which stop at the first row.
+// If MaxBinds is set, BuildStmt reports an error if the statement has more
+// binds, to stay below the parameters limit of the driver.
+// Vars holds the values that the compiler functions share, each under a key
+// named after the feature it belongs to (see the var* constants). They are
+// only ever looked up by key, never iterated, so the compilation does not
+// depend on the map order. The values that hold several items are slices in
+// compilation order, not maps, for the same reason.
 type CompilerContext struct {
 	Binds            []interface{}
 	DefaultTableName string
 	InSubQuery       bool
 	Vars             map[string]interface{}
+	Errors           []error
+	Strict           bool
+	Safe             bool
+	CheckBinds       bool
+	FetchFirst       bool
+	NullComparisons  bool
+	ArrayIn          bool
+	Placeholder      func(index int) string
+	MaxBinds         int
+	DedupBinds       bool
+	CountExists      bool
 
 	Dialect  Dialect
 	Compiler Compiler
 }
 
+// varCTEs is the Vars key of the names of the CTEs in scope, a []string in
+// declaration order
+const varCTEs = "with.ctes"
+
+// varParams is the Vars key of the named parameters of BindName(), a
+// map[string]interface{} that is only looked up by name
+const varParams = "binds.params"
+
+// varBindNames is the Vars key of the names of the binds, a []string of the
+// name of the column each bind is compared or assigned to, in the binds
+// order. It may be shorter than the binds, the missing names are empty
+const varBindNames = "binds.names"
+
+// compileNamed compiles a clause and gives the binds it adds, that are not
+// already named, the given name. An empty name leaves them unnamed
+func compileNamed(context *CompilerContext, name string, clause Clause) string {
+	from := len(context.Binds)
+	sql := clause.Accept(context)
+	if name == "" || len(context.Binds) == from {
+		return sql
+	}
+	names, _ := context.Vars[varBindNames].([]string)
+	for len(names) < len(context.Binds) {
+		names = append(names, "")
+	}
+	for i := from; i < len(context.Binds); i++ {
+		if names[i] == "" {
+			names[i] = name
+		}
+	}
+	context.Vars[varBindNames] = names
+	return sql
+}
+
+// columnBindName returns the name given to the binds compared to a clause, the
+// column name if it is a column
+func columnBindName(clause Clause) string {
+	if col, ok := clause.(ColumnElem); ok {
+		return col.Name
+	}
+	return ""
+}
+
+// AddError records an error found during the compilation. The compilation
+// goes on so all the errors of a statement can be collected
+func (c *CompilerContext) AddError(err error) {
+	c.Errors = append(c.Errors, err)
+}
+
+// SetParams sets the values of the named parameters of the BindName()
+// clauses
+func (c *CompilerContext) SetParams(params map[string]interface{}) {
+	c.Vars[varParams] = params
+}
+
+// Compile compiles any clause, like a condition, with the given dialect and
+// returns its SQL and binds. It is useful to build and test query fragments.
+// The compilation errors are not reported, use BuildStmt to get them
+func Compile(clause Clause, dialect Dialect) (string, []interface{}) {
+	context := NewCompilerContext(dialect)
+	return clause.Accept(context), context.Binds
+}
+
+// compileApart compiles a clause in a new context that has the same table
+// scope as the given one, so the given context binds are left untouched.
+// It is useful to compare clauses.
+func compileApart(context *CompilerContext, clause Clause) string {
+	apart := NewCompilerContext(context.Dialect)
+	apart.DefaultTableName = context.DefaultTableName
+	apart.InSubQuery = context.InSubQuery
+	return clause.Accept(apart)
+}
+
 // Compiler is a visitor that produce SQL from various types of Clause
 type Compiler interface {
 	VisitAggregate(*CompilerContext, AggregateClause) string
 	VisitAlias(*CompilerContext, AliasClause) string
+	VisitAliasRef(*CompilerContext, AliasRefClause) string
+	VisitAs(*CompilerContext, AsClause) string
+	VisitBetween(*CompilerContext, BetweenClause) string
 	VisitBinary(*CompilerContext, BinaryExpressionClause) string
 	VisitBind(*CompilerContext, BindClause) string
+	VisitCase(*CompilerContext, CaseClause) string
 	VisitColumn(*CompilerContext, ColumnElem) string
 	VisitCombiner(*CompilerContext, CombinerClause) string
+	VisitCompound(*CompilerContext, CompoundStmt) string
+	VisitCreateTableAs(*CompilerContext, CreateTableAsStmt) string
+	VisitCTE(*CompilerContext, CTEClause) string
+	VisitDefaultValue(*CompilerContext, DefaultValueClause) string
 	VisitDelete(*CompilerContext, DeleteStmt) string
+	VisitDistinctOn(*CompilerContext, DistinctOnClause) string
+	VisitEmbed(*CompilerContext, EmbedClause) string
+	VisitExcluded(*CompilerContext, ExcludedClause) string
 	VisitExists(*CompilerContext, ExistsClause) string
+	VisitFragment(*CompilerContext, FragmentClause) string
+	VisitFunc(*CompilerContext, FuncClause) string
+	VisitGroup(*CompilerContext, GroupClause) string
 	VisitHaving(*CompilerContext, HavingClause) string
 	VisitIn(*CompilerContext, InClause) string
 	VisitInsert(*CompilerContext, InsertStmt) string
+	VisitInto(*CompilerContext, IntoClause) string
 	VisitJoin(*CompilerContext, JoinClause) string
 	VisitLabel(*CompilerContext, string) string
+	VisitLikeAny(*CompilerContext, LikeAnyClause) string
+	VisitLimit(*CompilerContext, LimitClause) string
 	VisitList(*CompilerContext, ListClause) string
+	VisitLock(*CompilerContext, LockClause) string
+	VisitMerge(*CompilerContext, MergeStmt) string
+	VisitNamedBind(*CompilerContext, NamedBindClause) string
 	VisitOrderBy(*CompilerContext, OrderByClause) string
+	VisitOrdering(*CompilerContext, OrderingClause) string
 	VisitSelect(*CompilerContext, SelectStmt) string
+	VisitStringLiteral(*CompilerContext, StringLiteralClause) string
+	VisitSubquery(*CompilerContext, SubqueryClause) string
 	VisitTable(*CompilerContext, TableElem) string
+	VisitTableSample(*CompilerContext, TableSampleClause) string
 	VisitText(*CompilerContext, TextClause) string
+	VisitUnary(*CompilerContext, UnaryClause) string
 	VisitUpdate(*CompilerContext, UpdateStmt) string
 	VisitUpsert(*CompilerContext, UpsertStmt) string
+	VisitValues(*CompilerContext, ValuesClause) string
 	VisitWhere(*CompilerContext, WhereClause) string
+	VisitWindow(*CompilerContext, WindowClause) string
+	VisitWith(*CompilerContext, WithClause) string
 }
 
 // SQLCompiler aims to provide a SQL ANSI-92 implementation of Compiler
@@ -61,46 +211,232 @@ type SQLCompiler struct {
 
 // VisitAggregate compiles aggregate functions (COUNT, SUM...)
 func (c SQLCompiler) VisitAggregate(context *CompilerContext, aggregate AggregateClause) string {
-	return fmt.Sprintf("%s(%s)", aggregate.fn, aggregate.clause.Accept(context))
+	distinct := ""
+	if aggregate.distinct {
+		distinct = "DISTINCT "
+	}
+	args := aggregate.clause.Accept(context)
+	for _, arg := range aggregate.args {
+		args += ", " + arg.Accept(context)
+	}
+	orderBy := ""
+	if aggregate.orderBy != nil {
+		if context.Strict && aggregate.distinct {
+			checkAggregateDistinctOrderBy(context, aggregate)
+		}
+		orderBy = " " + aggregate.orderBy.Accept(context)
+	}
+	sql := fmt.Sprintf("%s(%s%s%s)", aggregate.fn, distinct, args, orderBy)
+	if aggregate.filter != nil {
+		sql += fmt.Sprintf(" FILTER (%s)", aggregate.filter.Accept(context))
+	}
+	return sql
+}
+
+// checkAggregateDistinctOrderBy reports the ORDER BY expressions of a
+// DISTINCT aggregate that are not the aggregated expression, which postgres
+// rejects
+func checkAggregateDistinctOrderBy(context *CompilerContext, aggregate AggregateClause) {
+	aggregated := compileApart(context, aggregate.clause)
+	for _, c := range aggregate.orderBy.columns {
+		if ordering, ok := c.(OrderingClause); ok {
+			c = ordering.Clause
+		}
+		if sql := compileApart(context, c); sql != aggregated {
+			context.AddError(fmt.Errorf(
+				"ORDER BY expression %s must be the aggregated expression of a %s(DISTINCT ...)", sql, aggregate.fn))
+		}
+	}
 }
 
 // VisitAlias compiles a '<selectable> AS <aliasname>' SQL clause
+// An aliased values list is followed by the names of its columns
 func (SQLCompiler) VisitAlias(context *CompilerContext, alias AliasClause) string {
-	return fmt.Sprintf(
+	sql := fmt.Sprintf(
 		"%s AS %s",
 		alias.Selectable.Accept(context),
-		context.Dialect.Escape(alias.Name),
+		context.Compiler.VisitLabel(context, alias.Name),
+	)
+	if values, ok := alias.Selectable.(ValuesClause); ok {
+		columns := []string{}
+		for _, column := range values.Columns {
+			columns = append(columns, context.Compiler.VisitLabel(context, column))
+		}
+		sql += fmt.Sprintf("(%s)", strings.Join(columns, ", "))
+	}
+	return sql
+}
+
+// VisitAliasRef returns the name of a select list alias, optionally escaped
+func (SQLCompiler) VisitAliasRef(context *CompilerContext, ref AliasRefClause) string {
+	return context.Compiler.VisitLabel(context, ref.Name)
+}
+
+// VisitAs compiles a '<clause> AS <aliasname>' SQL clause
+func (SQLCompiler) VisitAs(context *CompilerContext, as AsClause) string {
+	return fmt.Sprintf(
+		"%s AS %s",
+		as.Clause.Accept(context),
+		context.Compiler.VisitLabel(context, as.Name),
+	)
+}
+
+// VisitBetween compiles a <left> (NOT) BETWEEN <lower> AND <upper> expression
+func (c SQLCompiler) VisitBetween(context *CompilerContext, between BetweenClause) string {
+	op := "BETWEEN"
+	if between.Not {
+		op = "NOT BETWEEN"
+	}
+	return fmt.Sprintf(
+		"%s %s %s AND %s",
+		between.Left.Accept(context),
+		op,
+		compileNamed(context, columnBindName(between.Left), between.Lower),
+		compileNamed(context, columnBindName(between.Left), between.Upper),
 	)
 }
 
 // VisitBinary compiles LEFT <op> RIGHT expressions
+// The bitwise XOR has no standard operator, it is not supported
 func (c SQLCompiler) VisitBinary(context *CompilerContext, binary BinaryExpressionClause) string {
+	if binary.Op == bitXor {
+		context.AddError(errors.New("Bitwise XOR is not supported by this compiler"))
+	}
+	return compileBinary(context, binary)
+}
+
+// compileBinary compiles a LEFT <op> RIGHT expression with the operator as is
+func compileBinary(context *CompilerContext, binary BinaryExpressionClause) string {
+	if context.CountExists {
+		if exists, ok := countExists(binary); ok {
+			return exists.Accept(context)
+		}
+	}
+	if context.NullComparisons {
+		if bind, ok := binary.Right.(BindClause); ok && bind.Value == nil {
+			switch binary.Op {
+			case "=":
+				return fmt.Sprintf("%s IS NULL", binary.Left.Accept(context))
+			case "!=":
+				return fmt.Sprintf("%s IS NOT NULL", binary.Left.Accept(context))
+			}
+		}
+	}
 	return fmt.Sprintf(
 		"%s %s %s",
 		binary.Left.Accept(context),
 		binary.Op,
-		binary.Right.Accept(context),
+		compileNamed(context, columnBindName(binary.Left), binary.Right),
 	)
 }
 
+// countExists returns the EXISTS clause equivalent to a comparison of a
+// COUNT(*) subquery to 0: (SELECT COUNT(*) ...) > 0 is EXISTS(SELECT 1 ...),
+// (SELECT COUNT(*) ...) = 0 is NOT EXISTS(SELECT 1 ...). It returns false if
+// the comparison has no equivalent
+func countExists(binary BinaryExpressionClause) (ExistsClause, bool) {
+	var sel SelectStmt
+	switch left := binary.Left.(type) {
+	case SubqueryClause:
+		sel = left.Select
+	case SelectStmt:
+		sel = left
+	default:
+		return ExistsClause{}, false
+	}
+	if len(sel.sel) != 1 || len(sel.groupBy) != 0 || len(sel.having) != 0 ||
+		sel.offset != nil || sel.count != nil {
+		return ExistsClause{}, false
+	}
+	aggregate, ok := sel.sel[0].(AggregateClause)
+	if !ok || aggregate.fn != "COUNT" || aggregate.distinct || aggregate.filter != nil ||
+		!reflect.DeepEqual(aggregate.clause, SQLText("*")) {
+		return ExistsClause{}, false
+	}
+	bind, ok := binary.Right.(BindClause)
+	if !ok || bind.Value == nil {
+		return ExistsClause{}, false
+	}
+	switch value := reflect.ValueOf(bind.Value); value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Int() != 0 {
+			return ExistsClause{}, false
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() != 0 {
+			return ExistsClause{}, false
+		}
+	default:
+		return ExistsClause{}, false
+	}
+
+	sel.sel = []Clause{SQLText("1")}
+	sel.orderBy = nil
+	switch binary.Op {
+	case ">", "!=", "<>":
+		return Exists(sel), true
+	case "=":
+		return NotExists(sel), true
+	}
+	return ExistsClause{}, false
+}
+
 // VisitBind renders a bounded value
 func (SQLCompiler) VisitBind(context *CompilerContext, bind BindClause) string {
+	if context.Placeholder != nil {
+		return context.Placeholder(addBind(context, bind.Value))
+	}
 	context.Binds = append(context.Binds, bind.Value)
 	return "?"
 }
 
+// addBind adds a value to the binds of a statement rendered with numbered
+// placeholders, and returns its 1-based index. With DedupBinds, the index of
+// an equal value already bound is returned instead. The values that are not
+// comparable, or nil, are always added
+func addBind(context *CompilerContext, value interface{}) int {
+	if context.DedupBinds && value != nil && reflect.TypeOf(value).Comparable() {
+		for i, bound := range context.Binds {
+			if bound == value {
+				return i + 1
+			}
+		}
+	}
+	context.Binds = append(context.Binds, value)
+	return len(context.Binds)
+}
+
+// VisitCase compiles a CASE expression
+func (c SQLCompiler) VisitCase(context *CompilerContext, caseClause CaseClause) string {
+	sql := "CASE"
+	if caseClause.expr != nil {
+		sql += " " + caseClause.expr.Accept(context)
+	}
+	if len(caseClause.whens) == 0 {
+		context.AddError(errors.New("CASE has no WHEN branch"))
+	}
+	for _, when := range caseClause.whens {
+		sql += fmt.Sprintf(" WHEN %s THEN %s", when.Condition.Accept(context), when.Result.Accept(context))
+	}
+	if caseClause.elseResult != nil {
+		sql += " ELSE " + caseClause.elseResult.Accept(context)
+	}
+	return sql + " END"
+}
+
 // VisitColumn returns a column name, optionnaly escaped depending on the dialect
 // configuration
 func (c SQLCompiler) VisitColumn(context *CompilerContext, column ColumnElem) string {
 	sql := ""
 	if context.InSubQuery || context.DefaultTableName != column.Table {
-		sql += c.Dialect.Escape(column.Table) + "."
+		sql += context.Compiler.VisitLabel(context, column.Table) + "."
 	}
-	sql += c.Dialect.Escape(column.Name)
+	sql += context.Compiler.VisitLabel(context, column.Name)
 	return sql
 }
 
-// VisitCombiner compiles AND and OR sql clauses
+// VisitCombiner compiles AND and OR sql clauses, wrapped in a COALESCE if
+// they have a NULL replacement value
 func (c SQLCompiler) VisitCombiner(context *CompilerContext, combiner CombinerClause) string {
 	sqls := []string{}
 	for _, c := range combiner.clauses {
@@ -108,29 +444,302 @@ func (c SQLCompiler) VisitCombiner(context *CompilerContext, combiner CombinerCl
 		sqls = append(sqls, sql)
 	}
 
-	return fmt.Sprintf("(%s)", strings.Join(sqls, fmt.Sprintf(" %s ", combiner.operator)))
+	sql := fmt.Sprintf("(%s)", strings.Join(sqls, fmt.Sprintf(" %s ", combiner.operator)))
+	if combiner.nullAs != nil {
+		value := "FALSE"
+		if *combiner.nullAs {
+			value = "TRUE"
+		}
+		sql = fmt.Sprintf("COALESCE(%s, %s)", sql, value)
+	}
+	return sql
+}
+
+// VisitFunc compiles a function call. The parts of a schema-qualified name
+// are escaped, an unqualified name is not
+func (c SQLCompiler) VisitFunc(context *CompilerContext, fn FuncClause) string {
+	name := fn.Name
+	if parts := fn.Parts(); len(parts) > 1 {
+		for i, part := range parts {
+			parts[i] = context.Compiler.VisitLabel(context, part)
+		}
+		name = strings.Join(parts, ".")
+	}
+	return fmt.Sprintf("%s(%s)", name, List(fn.Args...).Accept(context))
+}
+
+// VisitGroup compiles a parenthesized clause
+func (c SQLCompiler) VisitGroup(context *CompilerContext, group GroupClause) string {
+	sql := group.clause.Accept(context)
+	if _, ok := group.clause.(CombinerClause); ok {
+		return sql
+	}
+	return fmt.Sprintf("(%s)", sql)
+}
+
+// VisitCompound compiles a compound select statement, its members being
+// parenthesized
+func (SQLCompiler) VisitCompound(context *CompilerContext, compound CompoundStmt) string {
+	return compileCompound(context, compound, true)
+}
+
+// compileCompound compiles a compound select statement: its members joined
+// by the set operator, then the ORDER BY and LIMIT of the whole result.
+// The ORDER BY columns of the first member are not qualified
+func compileCompound(context *CompilerContext, compound CompoundStmt, parenthesize bool) string {
+	if len(compound.selects) < 2 {
+		context.AddError(fmt.Errorf("%s needs at least 2 select statements", compound.operator))
+	}
+	lines := []string{}
+	if len(compound.with.CTEs) > 0 {
+		defer saveCTEs(context)()
+		lines = append(lines, compound.with.Accept(context))
+	}
+	members := []string{}
+	for _, sel := range compound.selects {
+		sql := sel.Accept(context)
+		if parenthesize {
+			sql = fmt.Sprintf("(%s)", sql)
+		}
+		members = append(members, sql)
+	}
+	lines = append(lines, strings.Join(members, fmt.Sprintf("\n%s\n", compound.operator)))
+
+	if compound.orderBy != nil {
+		if len(compound.selects) > 0 && compound.selects[0].from != nil {
+			context.DefaultTableName = compound.selects[0].from.DefaultName()
+		}
+		lines = append(lines, compound.orderBy.Accept(context))
+	}
+	if compound.offset != nil || compound.count != nil {
+		lines = append(lines, compileLimit(context, compound.offset, compound.count))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// VisitCreateTableAs compiles a CREATE TABLE AS statement
+// WITH NO DATA is not ANSI, the dialects that support it must override this
+// function
+func (SQLCompiler) VisitCreateTableAs(context *CompilerContext, create CreateTableAsStmt) string {
+	if create.noData {
+		context.AddError(errors.New("WITH NO DATA is not supported by this compiler"))
+	}
+	return compileCreateTableAs(context, create)
+}
+
+// compileCreateTableAs compiles a CREATE TABLE AS statement, without its
+// WITH NO DATA option
+func compileCreateTableAs(context *CompilerContext, create CreateTableAsStmt) string {
+	ifNotExists := ""
+	if create.ifNotExists {
+		ifNotExists = "IF NOT EXISTS "
+	}
+	return fmt.Sprintf(
+		"CREATE TABLE %s%s AS\n%s",
+		ifNotExists,
+		context.Compiler.VisitLabel(context, create.name),
+		create.sel.Accept(context),
+	)
+}
+
+// VisitCTE returns a CTE name, optionally escaped
+// In strict mode, it checks that the CTE is declared by an enclosing WITH
+func (SQLCompiler) VisitCTE(context *CompilerContext, cte CTEClause) string {
+	if context.Strict {
+		declared := false
+		names, _ := context.Vars[varCTEs].([]string)
+		for _, name := range names {
+			declared = declared || name == cte.Name
+		}
+		if !declared {
+			context.AddError(fmt.Errorf("CTE %s is not declared in a WITH clause", cte.Name))
+		}
+	}
+	return context.Compiler.VisitLabel(context, cte.Name)
+}
+
+// compileReturning compiles the RETURNING clause of an INSERT, UPDATE, DELETE
+// or upsert statement, preceded by a newline. The columns of the
+// defaultTableName table are not qualified, give an empty name to qualify
+// them all when several tables are in scope
+func compileReturning(context *CompilerContext, defaultTableName string, returning []Clause) string {
+	if len(returning) == 0 {
+		return ""
+	}
+	defer func(name string) { context.DefaultTableName = name }(context.DefaultTableName)
+	context.DefaultTableName = defaultTableName
+
+	items := []string{}
+	for _, r := range returning {
+		items = append(items, r.Accept(context))
+	}
+	return "\nRETURNING " + strings.Join(items, ", ")
+}
+
+// sortedKeys returns the sorted keys of the values of a statement, so the
+// columns and their binds are compiled in a stable order
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsString returns true if the string is one of the slice items
+func containsString(items []string, str string) bool {
+	for _, item := range items {
+		if item == str {
+			return true
+		}
+	}
+	return false
+}
+
+// compileUpsertUpdates compiles the assignments of the update that runs if
+// the row of an upsert already exists
+func compileUpsertUpdates(context *CompilerContext, upsert UpsertStmt) string {
+	updates := []string{}
+	values := upsert.updateValues()
+	for _, k := range sortedKeys(values) {
+		updates = append(updates, fmt.Sprintf(
+			"%s = %s",
+			context.Compiler.VisitLabel(context, k),
+			compileNamed(context, k, GetClauseFrom(values[k])),
+		))
+	}
+	return strings.Join(updates, ", ")
+}
+
+// compileOnConflictUpsert compiles an upsert as a
+// INSERT INTO ... VALUES ... ON CONFLICT(...) DO UPDATE SET ...
+// or as a INSERT INTO ... VALUES ... ON CONFLICT(...) DO NOTHING
+func compileOnConflictUpsert(context *CompilerContext, upsert UpsertStmt) string {
+	var (
+		colNames []string
+		values   []string
+	)
+	for _, k := range sortedKeys(upsert.values) {
+		colNames = append(colNames, context.Compiler.VisitLabel(context, k))
+		values = append(values, compileNamed(context, k, Bind(upsert.values[k])))
+	}
+
+	var target string
+	if upsert.constraint != "" {
+		if len(upsert.conflict) > 0 || len(upsert.exprs) > 0 {
+			context.AddError(errors.New("Upsert cannot have both conflict columns and a constraint"))
+		}
+		target = " ON CONSTRAINT " + context.Compiler.VisitLabel(context, upsert.constraint)
+	} else {
+		var uniqueCols []string
+		if len(upsert.conflict) > 0 || len(upsert.exprs) > 0 {
+			for _, name := range upsert.conflict {
+				uniqueCols = append(uniqueCols, context.Compiler.VisitLabel(context, name))
+			}
+			defaultTableName := context.DefaultTableName
+			context.DefaultTableName = upsert.table.Name
+			for _, expr := range upsert.exprs {
+				uniqueCols = append(uniqueCols, fmt.Sprintf("(%s)", expr.Accept(context)))
+			}
+			context.DefaultTableName = defaultTableName
+		} else {
+			for _, c := range upsert.table.PrimaryCols() {
+				uniqueCols = append(uniqueCols, context.Compiler.VisitLabel(context, c.Name))
+			}
+		}
+		// DO NOTHING can apply to any conflict, DO UPDATE needs a target
+		if len(uniqueCols) > 0 {
+			target = fmt.Sprintf(" (%s)", strings.Join(uniqueCols, ", "))
+		} else if !upsert.doNothing {
+			context.AddError(fmt.Errorf(
+				"Upsert DO UPDATE needs conflict columns, a constraint or a primary key on table %s",
+				upsert.table.Name))
+		}
+	}
+
+	sql := fmt.Sprintf(
+		"INSERT INTO %s(%s)\nVALUES(%s)\nON CONFLICT%s ",
+		context.Compiler.VisitLabel(context, upsert.table.Name),
+		strings.Join(colNames, ", "),
+		strings.Join(values, ", "),
+		target)
+
+	if upsert.doNothing {
+		if len(upsert.set) > 0 || upsert.where != nil {
+			context.AddError(errors.New("Upsert DO NOTHING cannot have update values or condition"))
+		}
+		sql += "DO NOTHING"
+	} else {
+		sql += "DO UPDATE SET " + compileUpsertUpdates(context, upsert)
+		if upsert.where != nil {
+			sql += "\n" + upsert.where.Accept(context)
+		}
+	}
+
+	sql += compileReturning(context, upsert.table.Name, upsert.returning)
+	return sql
+}
+
+// VisitDefaultValue returns the DEFAULT keyword
+func (SQLCompiler) VisitDefaultValue(context *CompilerContext, def DefaultValueClause) string {
+	return "DEFAULT"
 }
 
 // VisitDelete compiles a DELETE statement
 func (c SQLCompiler) VisitDelete(context *CompilerContext, delete DeleteStmt) string {
-	sql := "DELETE FROM " + delete.table.Accept(context)
+	if context.Safe && delete.where == nil && !delete.unsafe {
+		context.AddError(errors.New("Delete without WHERE clause, use Unsafe() to delete all the rows"))
+	}
+	if delete.orderBy != nil || delete.count != nil {
+		context.AddError(errors.New("DELETE ... ORDER BY and LIMIT are not supported by this compiler"))
+	}
+
+	sql := ""
+	if len(delete.with.CTEs) > 0 {
+		defer saveCTEs(context)()
+		sql = delete.with.Accept(context) + "\n"
+	}
+
+	sql += "DELETE FROM " + delete.table.Accept(context)
 
 	if delete.where != nil {
 		sql += "\n" + delete.where.Accept(context)
 	}
 
-	returning := []string{}
-	for _, c := range delete.returning {
-		returning = append(returning, context.Dialect.Escape(c.Name))
-	}
+	sql += compileReturning(context, delete.table.Name, delete.returning)
 
-	if len(returning) > 0 {
-		sql += "\nRETURNING " + strings.Join(returning, ", ")
-	}
+	return sql
+}
 
+// VisitDistinctOn is not implemented and will raise an error.
+// It should be implemented in the dialects that support DISTINCT ON
+func (SQLCompiler) VisitDistinctOn(context *CompilerContext, distinctOn DistinctOnClause) string {
+	context.AddError(errors.New("DISTINCT ON is not supported by this compiler"))
+	return ""
+}
+
+// VisitEmbed compiles an embedded statement, its binds being bound with the
+// placeholders of the dialect
+func (SQLCompiler) VisitEmbed(context *CompilerContext, embed EmbedClause) string {
+	if len(embed.Parts) != len(embed.Binds)+1 {
+		context.AddError(fmt.Errorf("The embedded statement has %d parts, %d expected for its %d binds",
+			len(embed.Parts), len(embed.Binds)+1, len(embed.Binds)))
+		return ""
+	}
+	sql := embed.Parts[0]
+	for i, value := range embed.Binds {
+		sql += Bind(value).Accept(context) + embed.Parts[i+1]
+	}
 	return sql
 }
 
+// VisitExcluded compiles a reference to a column of the row proposed for
+// insertion by an upsert
+func (SQLCompiler) VisitExcluded(context *CompilerContext, excluded ExcludedClause) string {
+	return "EXCLUDED." + context.Compiler.VisitLabel(context, excluded.Column.Name)
+}
+
 // VisitExists compile a EXISTS clause
 func (SQLCompiler) VisitExists(context *CompilerContext, exists ExistsClause) string {
 	var sql string
@@ -143,55 +752,153 @@ func (SQLCompiler) VisitExists(context *CompilerContext, exists ExistsClause) st
 	return fmt.Sprintf(sql, exists.Select.Accept(context))
 }
 
-// VisitHaving compiles a HAVING clause
+// VisitFragment compiles the registered fragment the clause refers to
+func (SQLCompiler) VisitFragment(context *CompilerContext, fragment FragmentClause) string {
+	clause, ok := FragmentRegistry[fragment.Name]
+	if !ok {
+		context.AddError(fmt.Errorf("No such fragment: %s", fragment.Name))
+		return ""
+	}
+	return clause.Accept(context)
+}
+
+// VisitHaving compiles a HAVING condition, without the HAVING keyword
+// ANSI SQL does not allow a HAVING clause to reference a select list alias,
+// the dialects that do must override this function with compileHaving
 func (c SQLCompiler) VisitHaving(context *CompilerContext, having HavingClause) string {
-	aggSQL := having.aggregate.Accept(context)
-	return fmt.Sprintf("HAVING %s %s %s", aggSQL, having.op, Bind(having.value).Accept(context))
+	if _, ok := having.clause.(AliasRefClause); ok {
+		context.AddError(errors.New("HAVING cannot reference a select list alias in this compiler"))
+	}
+	return compileHaving(context, having)
+}
+
+// compileHaving compiles a HAVING condition without any check
+func compileHaving(context *CompilerContext, having HavingClause) string {
+	sql := having.clause.Accept(context)
+	return fmt.Sprintf("%s %s %s", sql, having.op, Bind(having.value).Accept(context))
 }
 
 // VisitIn compiles a <left> (NOT) IN (<right>)
+// If right is a select statement, it is compiled as a subquery. If it is a
+// single slice, its items are bound. If it is empty, as IN () is not valid,
+// it is compiled as an always false, or true for NOT IN, condition
 func (c SQLCompiler) VisitIn(context *CompilerContext, in InClause) string {
+	if list, ok := in.Right.(ListClause); ok {
+		if len(list.Clauses) == 1 {
+			if sel, ok := list.Clauses[0].(SelectStmt); ok {
+				if len(sel.sel) != 1 {
+					context.AddError(fmt.Errorf(
+						"IN subquery must select exactly one column, it selects %d", len(sel.sel)))
+				}
+				return fmt.Sprintf(
+					"%s %s %s",
+					in.Left.Accept(context),
+					in.Op,
+					Subquery(sel).Accept(context),
+				)
+			}
+			if bind, ok := list.Clauses[0].(BindClause); ok && isSlice(bind.Value) {
+				values := reflect.ValueOf(bind.Value)
+				list = List()
+				for i := 0; i < values.Len(); i++ {
+					list.Clauses = append(list.Clauses, Bind(values.Index(i).Interface()))
+				}
+				in.Right = list
+			}
+		}
+		if len(list.Clauses) == 0 {
+			if in.Op == "NOT IN" {
+				return "1 = 1"
+			}
+			return "1 = 0"
+		}
+	}
 	return fmt.Sprintf(
 		"%s %s (%s)",
 		in.Left.Accept(context),
 		in.Op,
-		in.Right.Accept(context),
+		compileNamed(context, columnBindName(in.Left), in.Right),
 	)
 }
 
+// isSlice returns true if a bind value is a slice or an array to be
+// expanded, but not a driver value or a slice of bytes (like a []byte, a
+// json.RawMessage or a net.IP) which are bound as a whole
+func isSlice(value interface{}) bool {
+	if _, ok := value.(driver.Valuer); ok {
+		return false
+	}
+	return isSliceValue(reflect.ValueOf(value))
+}
+
+// isSliceValue returns true if a value is a slice or an array, but not one
+// of bytes
+func isSliceValue(v reflect.Value) bool {
+	kind := v.Kind()
+	return (kind == reflect.Slice || kind == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8
+}
+
 // VisitInsert compiles a INSERT statement
 func (c SQLCompiler) VisitInsert(context *CompilerContext, insert InsertStmt) string {
 	context.DefaultTableName = insert.table.Name
 	defer func() { context.DefaultTableName = "" }()
 
-	cols := List()
-	values := List()
-	for k, v := range insert.values {
-		cols.Clauses = append(cols.Clauses, insert.table.C(k))
-		values.Clauses = append(values.Clauses, Bind(v))
+	if insert.structErr != nil {
+		context.AddError(insert.structErr)
 	}
 
-	sql := fmt.Sprintf(
-		"INSERT INTO %s(%s)\nVALUES(%s)",
-		insert.table.Accept(context),
-		cols.Accept(context),
-		values.Accept(context),
-	)
-
-	returning := []string{}
-	for _, r := range insert.returning {
-		returning = append(returning, r.Accept(context))
+	var sql string
+	if len(insert.with.CTEs) > 0 {
+		defer saveCTEs(context)()
+		sql = insert.with.Accept(context) + "\n"
 	}
-	if len(insert.returning) > 0 {
+
+	if insert.defaultValues {
+		if len(insert.values) > 0 {
+			context.AddError(errors.New("Insert cannot have both values and default values"))
+		}
+		sql += fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", insert.table.Accept(context))
+	} else if insert.sel != nil {
+		if len(insert.values) > 0 {
+			context.AddError(errors.New("Insert cannot have both values and a select"))
+		}
+		sql += "INSERT INTO " + insert.table.Accept(context)
+		if len(insert.selColumns) > 0 {
+			cols := List()
+			for _, name := range insert.selColumns {
+				cols.Clauses = append(cols.Clauses, insert.table.C(name))
+			}
+			sql += fmt.Sprintf("(%s)", cols.Accept(context))
+		}
+		sql += "\n" + insert.sel.Accept(context)
+	} else {
+		columns := insert.valueColumns()
+		cols := List()
+		for _, k := range columns {
+			cols.Clauses = append(cols.Clauses, insert.table.C(k))
+		}
 		sql += fmt.Sprintf(
-			"\nRETURNING %s",
-			strings.Join(returning, ", "),
+			"INSERT INTO %s(%s)\nVALUES(",
+			insert.table.Accept(context),
+			cols.Accept(context),
 		)
+		var values []string
+		for _, k := range columns {
+			values = append(values, compileNamed(context, k, GetClauseFrom(insert.values[k])))
+		}
+		sql += strings.Join(values, ", ") + ")"
 	}
 
+	sql += compileReturning(context, insert.table.Name, insert.returning)
+
 	return sql
 }
 
+// VisitInto compiles the INTO clause of a SELECT INTO statement
+func (SQLCompiler) VisitInto(context *CompilerContext, into IntoClause) string {
+	return fmt.Sprintf("INTO %s", into.Table.Accept(context))
+}
+
 // VisitJoin compiles a JOIN (ON) clause
 func (c SQLCompiler) VisitJoin(context *CompilerContext, join JoinClause) string {
 	sql := fmt.Sprintf(
@@ -208,8 +915,33 @@ func (c SQLCompiler) VisitJoin(context *CompilerContext, join JoinClause) string
 }
 
 // VisitLabel returns a single label, optionally escaped
+// In strict mode, a label that is a reserved word of the dialect is quoted
+// even if the dialect does not escape it
 func (c SQLCompiler) VisitLabel(context *CompilerContext, label string) string {
-	return c.Dialect.Escape(label)
+	escaped := c.Dialect.Escape(label)
+	if context.Strict && escaped == label && c.Dialect.IsReserved(label) {
+		return c.Dialect.Quote(label)
+	}
+	return escaped
+}
+
+// VisitLikeAny compiles a match against a list of patterns as ORed LIKE
+// The case insensitive version compares the lowered expression and patterns
+func (SQLCompiler) VisitLikeAny(context *CompilerContext, likeAny LikeAnyClause) string {
+	if len(likeAny.Patterns) == 0 {
+		context.AddError(errors.New("LIKE ANY needs at least one pattern"))
+	}
+	var conditions []string
+	for _, pattern := range likeAny.Patterns {
+		left := likeAny.Left.Accept(context)
+		right := Bind(pattern).Accept(context)
+		if likeAny.CaseInsensitive {
+			left = fmt.Sprintf("LOWER(%s)", left)
+			right = fmt.Sprintf("LOWER(%s)", right)
+		}
+		conditions = append(conditions, fmt.Sprintf("%s LIKE %s", left, right))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(conditions, " OR "))
 }
 
 // VisitList compiles a list of values
@@ -221,123 +953,687 @@ func (c SQLCompiler) VisitList(context *CompilerContext, list ListClause) string
 	return strings.Join(clauses, ", ")
 }
 
+// VisitLock compiles a FOR UPDATE [OF <tables>] [NOWAIT | SKIP LOCKED |
+// WAIT <n>] clause
+func (c SQLCompiler) VisitLock(context *CompilerContext, lock LockClause) string {
+	if lock.Timeout != nil {
+		if lock.Strategy != LockWait {
+			context.AddError(errors.New("FOR UPDATE WAIT cannot be combined with NOWAIT or SKIP LOCKED"))
+		}
+		if *lock.Timeout < 0 {
+			context.AddError(fmt.Errorf("Negative lock timeout %d", *lock.Timeout))
+		}
+	}
+	sql := "FOR " + lock.Strength
+	if len(lock.Of) > 0 {
+		tables := []string{}
+		for _, table := range lock.Of {
+			tables = append(tables, context.Compiler.VisitLabel(context, table.Name))
+		}
+		sql += " OF " + strings.Join(tables, ", ")
+	}
+	switch lock.Strategy {
+	case LockNoWait:
+		sql += " NOWAIT"
+	case LockSkipLocked:
+		sql += " SKIP LOCKED"
+	}
+	if lock.Timeout != nil {
+		sql += fmt.Sprintf(" WAIT %d", *lock.Timeout)
+	}
+	return sql
+}
+
+// VisitMerge compiles a MERGE statement
+// The binds follow the USING, ON and WHEN order, the values of each action
+// being sorted by column name
+func (c SQLCompiler) VisitMerge(context *CompilerContext, merge MergeStmt) string {
+	if merge.on == nil {
+		context.AddError(errors.New("Merge has no ON condition"))
+	}
+	if len(merge.actions) == 0 {
+		context.AddError(errors.New("Merge has no WHEN MATCHED or WHEN NOT MATCHED action"))
+	}
+
+	lines := []string{
+		"MERGE INTO " + merge.target.Accept(context),
+		"USING " + merge.source.Accept(context),
+	}
+	if merge.on != nil {
+		lines[1] += " ON " + merge.on.Accept(context)
+	}
+
+	for _, when := range merge.actions {
+		sql := "WHEN MATCHED"
+		if !when.matched {
+			sql = "WHEN NOT MATCHED"
+		}
+		action := when.action
+		if action.condition != nil {
+			sql += " AND " + action.condition.Accept(context)
+		}
+		sql += " THEN "
+
+		switch {
+		case action.kind == mergeDoNothing:
+			sql += "DO NOTHING"
+		case action.kind == mergeInsert && !when.matched:
+			columns := []string{}
+			values := []string{}
+			for _, k := range sortedKeys(action.values) {
+				columns = append(columns, context.Compiler.VisitLabel(context, k))
+				values = append(values, GetClauseFrom(action.values[k]).Accept(context))
+			}
+			sql += fmt.Sprintf("INSERT (%s) VALUES (%s)", strings.Join(columns, ", "), strings.Join(values, ", "))
+		case action.kind == mergeUpdate && when.matched:
+			sets := []string{}
+			for _, k := range sortedKeys(action.values) {
+				sets = append(sets, fmt.Sprintf("%s = %s",
+					context.Compiler.VisitLabel(context, k),
+					GetClauseFrom(action.values[k]).Accept(context)))
+			}
+			sql += "UPDATE SET " + strings.Join(sets, ", ")
+		case action.kind == mergeDelete && when.matched:
+			sql += "DELETE"
+		case when.matched:
+			context.AddError(fmt.Errorf("%s is not a valid WHEN MATCHED action", action.kind))
+		default:
+			context.AddError(fmt.Errorf("%s is not a valid WHEN NOT MATCHED action", action.kind))
+		}
+		if (action.kind == mergeInsert || action.kind == mergeUpdate) && len(action.values) == 0 {
+			context.AddError(fmt.Errorf("Merge %s action has no values", action.kind))
+		}
+		lines = append(lines, sql)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// VisitNamedBind renders the value of a named parameter as a bounded value
+// A parameter that has no value is reported as an error
+func (SQLCompiler) VisitNamedBind(context *CompilerContext, bind NamedBindClause) string {
+	params, _ := context.Vars[varParams].(map[string]interface{})
+	value, ok := params[bind.Name]
+	if !ok {
+		context.AddError(fmt.Errorf("Missing value for the named bind %s", bind.Name))
+	}
+	return compileNamed(context, bind.Name, Bind(value))
+}
+
+// compileLimit checks the offset and count of a statement and compiles its
+// LimitClause
+func compileLimit(context *CompilerContext, offset *int, count *int) string {
+	if offset != nil && *offset < 0 {
+		context.AddError(fmt.Errorf("Invalid negative offset: %d", *offset))
+	}
+	if count != nil && *count < 0 {
+		context.AddError(fmt.Errorf("Invalid negative limit: %d", *count))
+	}
+	return LimitClause{offset, count}.Accept(context)
+}
+
+// VisitLimit compiles the LIMIT and OFFSET of a select statement
+func (SQLCompiler) VisitLimit(context *CompilerContext, limit LimitClause) string {
+	lines := []string{}
+	if context.FetchFirst {
+		if limit.Offset != nil {
+			lines = append(lines, fmt.Sprintf("OFFSET %d ROWS", *limit.Offset))
+		}
+		if limit.Count != nil {
+			lines = append(lines, fmt.Sprintf("FETCH FIRST %d ROWS ONLY", *limit.Count))
+		}
+		return strings.Join(lines, "\n")
+	}
+	if limit.Count != nil {
+		lines = append(lines, fmt.Sprintf("LIMIT %d", *limit.Count))
+	}
+	if limit.Offset != nil {
+		lines = append(lines, fmt.Sprintf("OFFSET %d", *limit.Offset))
+	}
+	return strings.Join(lines, " ")
+}
+
 // VisitOrderBy compiles a ORDER BY sql clause
+// The direction of the clause is not rendered if the last expression has
+// its own
 func (c SQLCompiler) VisitOrderBy(context *CompilerContext, orderBy OrderByClause) string {
 	cols := []string{}
 	for _, c := range orderBy.columns {
 		cols = append(cols, c.Accept(context))
 	}
 
+	if len(orderBy.columns) > 0 {
+		if _, ok := orderBy.columns[len(orderBy.columns)-1].(OrderingClause); ok {
+			return fmt.Sprintf("ORDER BY %s", strings.Join(cols, ", "))
+		}
+	}
 	return fmt.Sprintf("ORDER BY %s %s", strings.Join(cols, ", "), orderBy.t)
 }
 
+// VisitOrdering compiles an order by expression with its direction
+func (c SQLCompiler) VisitOrdering(context *CompilerContext, ordering OrderingClause) string {
+	if ordering.Operator != "" {
+		context.AddError(errors.New("ORDER BY ... USING is not supported by this compiler"))
+	}
+	sql := fmt.Sprintf("%s %s", ordering.Clause.Accept(context), ordering.Direction)
+	if ordering.Nulls != "" {
+		sql += " NULLS " + ordering.Nulls
+	}
+	return sql
+}
+
 // VisitSelect compiles a SELECT statement
 func (c SQLCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt) string {
 	lines := []string{}
 	addLine := func(s string) {
 		lines = append(lines, s)
 	}
+	addRaw := func(position RawPosition) {
+		for _, raw := range selectStmt.raw {
+			if raw.Position == position {
+				addLine(raw.Clause.Accept(context))
+			}
+		}
+	}
+
+	// with
+	if len(selectStmt.with.CTEs) > 0 {
+		defer saveCTEs(context)()
+		addLine(selectStmt.with.Accept(context))
+	}
+
 	if !context.InSubQuery && selectStmt.from != nil {
 		context.DefaultTableName = selectStmt.from.DefaultName()
 	}
 
 	// select
+	if len(selectStmt.sel) == 0 {
+		context.AddError(errors.New("Select has no columns"))
+	}
+	sql := "SELECT "
+	if len(selectStmt.distinctOn) > 0 {
+		sql += DistinctOnClause{selectStmt.distinctOn}.Accept(context) + " "
+	} else if selectStmt.distinct {
+		sql += "DISTINCT "
+	}
 	columns := []string{}
 	for _, c := range selectStmt.sel {
-		sql := c.Accept(context)
-		columns = append(columns, sql)
+		if col, ok := c.(ColumnElem); ok && context.Strict && selectStmt.from == nil && col.Table != "" {
+			context.AddError(fmt.Errorf("Select of column %s.%s has no FROM clause", col.Table, col.Name))
+		}
+		columns = append(columns, c.Accept(context))
+	}
+	addLine(sql + strings.Join(columns, ", "))
+
+	// into
+	if selectStmt.into != nil {
+		addLine(selectStmt.into.Accept(context))
 	}
-	addLine(fmt.Sprintf("SELECT %s", strings.Join(columns, ", ")))
 
 	// from
 	if selectStmt.from != nil {
 		addLine(fmt.Sprintf("FROM %s", selectStmt.from.Accept(context)))
 	}
+	addRaw(RawAfterFrom)
 
 	// where
 	if selectStmt.WhereClause != nil {
 		addLine(selectStmt.WhereClause.Accept(context))
 	}
+	addRaw(RawAfterWhere)
 
 	// group by
 	groupByCols := []string{}
 	for _, c := range selectStmt.groupBy {
-		groupByCols = append(groupByCols, context.Dialect.Escape(c.Name))
+		if col, ok := c.(ColumnElem); ok {
+			groupByCols = append(groupByCols, context.Compiler.VisitLabel(context, col.Name))
+		} else {
+			groupByCols = append(groupByCols, c.Accept(context))
+		}
 	}
-	if len(groupByCols) > 0 {
+	if selectStmt.groupByAll {
+		if len(groupByCols) > 0 {
+			context.AddError(errors.New("GROUP BY ALL cannot be combined with GROUP BY columns"))
+		}
+		addLine("GROUP BY ALL")
+	} else if len(groupByCols) > 0 {
+		if context.Strict {
+			checkGroupBy(context, selectStmt)
+		}
 		addLine(fmt.Sprintf("GROUP BY %s", strings.Join(groupByCols, ", ")))
 	}
 
 	// having
+	havingConditions := []string{}
 	for _, h := range selectStmt.having {
-		sql := h.Accept(context)
-		addLine(sql)
+		havingConditions = append(havingConditions, h.Accept(context))
+	}
+	if len(havingConditions) > 0 {
+		addLine(fmt.Sprintf("HAVING %s", strings.Join(havingConditions, " AND ")))
 	}
 
 	// order by
 	if selectStmt.orderBy != nil {
+		if context.Strict && selectStmt.distinct {
+			checkDistinctOrderBy(context, selectStmt)
+		}
 		sql := selectStmt.orderBy.Accept(context)
 		addLine(sql)
 	}
 
-	if (selectStmt.offset != nil) && (selectStmt.count != nil) {
-		addLine(fmt.Sprintf("LIMIT %d OFFSET %d", *selectStmt.count, *selectStmt.offset))
+	if selectStmt.offset != nil || selectStmt.count != nil {
+		addLine(compileLimit(context, selectStmt.offset, selectStmt.count))
+	}
+
+	if selectStmt.lock != nil {
+		addLine(selectStmt.lock.Accept(context))
 	}
+	addRaw(RawTrailing)
 
 	return strings.Join(lines, "\n")
 }
 
+// VisitStringLiteral returns a string literal quoted by the dialect
+func (SQLCompiler) VisitStringLiteral(context *CompilerContext, literal StringLiteralClause) string {
+	return context.Dialect.QuoteLiteral(literal.Value)
+}
+
+// VisitSubquery compiles a parenthesized subquery
+func (SQLCompiler) VisitSubquery(context *CompilerContext, subquery SubqueryClause) string {
+	inSubQuery := context.InSubQuery
+	context.InSubQuery = true
+	defer func() { context.InSubQuery = inSubQuery }()
+	return fmt.Sprintf("(%s)", subquery.Select.Accept(context))
+}
+
+// expandGroupByAll returns the statement with its GROUP BY ALL replaced by
+// the explicit list of the selected expressions that are not aggregated, for
+// the dialects that do not support it. The columns are grouped by name, the
+// other expressions by their position in the select list, so their binds
+// are not bound a second time as different placeholders
+func expandGroupByAll(selectStmt SelectStmt) SelectStmt {
+	if !selectStmt.groupByAll || len(selectStmt.groupBy) > 0 {
+		return selectStmt
+	}
+	groupBy := []Clause{}
+	for i, c := range selectStmt.sel {
+		if as, ok := c.(AsClause); ok {
+			c = as.Clause
+		}
+		if isAggregated(c) || isConstant(c) {
+			continue
+		}
+		if _, ok := c.(ColumnElem); ok {
+			groupBy = append(groupBy, c)
+		} else {
+			groupBy = append(groupBy, SQLText(strconv.Itoa(i+1)))
+		}
+	}
+	selectStmt.groupByAll = false
+	selectStmt.groupBy = groupBy
+	return selectStmt
+}
+
+// isAggregated returns true if an expression is, or is computed from, an
+// aggregate or a window function
+func isAggregated(clause Clause) bool {
+	return anySubClause(clause, func(c Clause) bool {
+		switch c.(type) {
+		case AggregateClause, WindowClause:
+			return true
+		}
+		return false
+	})
+}
+
+// isConstant returns true if an expression is a bound value or a literal, or
+// is only computed from them. Grouping by it is useless, and would bind its
+// values twice
+func isConstant(clause Clause) bool {
+	switch c := clause.(type) {
+	case BindClause, NamedBindClause, StringLiteralClause:
+		return true
+	case AsClause:
+		return isConstant(c.Clause)
+	case BinaryExpressionClause:
+		return isConstant(c.Left) && isConstant(c.Right)
+	case UnaryClause:
+		return isConstant(c.Clause)
+	case FuncClause:
+		for _, arg := range c.Args {
+			if !isConstant(arg) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// anySubClause returns true if the clause, or one of the clauses of the
+// expression it is built from, matches
+func anySubClause(clause Clause, match func(Clause) bool) bool {
+	if clause == nil {
+		return false
+	}
+	if match(clause) {
+		return true
+	}
+	var subs []Clause
+	switch c := clause.(type) {
+	case AsClause:
+		subs = []Clause{c.Clause}
+	case BinaryExpressionClause:
+		subs = []Clause{c.Left, c.Right}
+	case InClause:
+		subs = []Clause{c.Left, c.Right}
+	case BetweenClause:
+		subs = []Clause{c.Left, c.Lower, c.Upper}
+	case UnaryClause:
+		subs = []Clause{c.Clause}
+	case FuncClause:
+		subs = c.Args
+	case ListClause:
+		subs = c.Clauses
+	case CombinerClause:
+		subs = c.clauses
+	case GroupClause:
+		subs = []Clause{c.clause}
+	case CaseClause:
+		subs = []Clause{c.expr, c.elseResult}
+		for _, when := range c.whens {
+			subs = append(subs, when.Condition, when.Result)
+		}
+	}
+	for _, sub := range subs {
+		if anySubClause(sub, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGroupBy reports the selected columns of a grouped SELECT that are
+// neither grouped nor aggregated, which the ANSI SQL forbids
+func checkGroupBy(context *CompilerContext, selectStmt SelectStmt) {
+	grouped := map[[2]string]bool{}
+	groupedRefs := map[string]bool{}
+	for _, c := range selectStmt.groupBy {
+		if col, ok := c.(ColumnElem); ok {
+			grouped[[2]string{col.Table, col.Name}] = true
+		} else {
+			groupedRefs[compileApart(context, c)] = true
+		}
+	}
+	var offending []string
+	for _, c := range selectStmt.sel {
+		if as, ok := c.(AsClause); ok {
+			if groupedRefs[compileApart(context, as.Ref())] {
+				continue
+			}
+			c = as.Clause
+		}
+		if col, ok := c.(ColumnElem); ok && !grouped[[2]string{col.Table, col.Name}] {
+			offending = append(offending, compileApart(context, col))
+		}
+	}
+	if len(offending) > 0 {
+		context.AddError(fmt.Errorf(
+			"Selected columns must appear in the GROUP BY clause or be aggregated: %s",
+			strings.Join(offending, ", ")))
+	}
+}
+
+// checkDistinctOrderBy reports the ORDER BY expressions of a SELECT DISTINCT
+// that are not in the select list, which most databases reject
+func checkDistinctOrderBy(context *CompilerContext, selectStmt SelectStmt) {
+	selected := map[string]bool{}
+	for _, c := range selectStmt.sel {
+		selected[compileApart(context, c)] = true
+		if as, ok := c.(AsClause); ok {
+			selected[compileApart(context, as.Clause)] = true
+			selected[compileApart(context, as.Ref())] = true
+		}
+	}
+	for _, c := range selectStmt.orderBy.columns {
+		if ordering, ok := c.(OrderingClause); ok {
+			c = ordering.Clause
+		}
+		if sql := compileApart(context, c); !selected[sql] {
+			context.AddError(fmt.Errorf(
+				"ORDER BY expression %s must appear in the select list of a SELECT DISTINCT", sql))
+		}
+	}
+}
+
 // VisitTable returns a table name, optionally escaped
 func (SQLCompiler) VisitTable(context *CompilerContext, table TableElem) string {
 	return context.Compiler.VisitLabel(context, table.Name)
 }
 
-// VisitText return a raw SQL clause as is
+// VisitTableSample is not implemented and will raise an error.
+// It should be implemented in the dialects that support TABLESAMPLE
+func (SQLCompiler) VisitTableSample(context *CompilerContext, sample TableSampleClause) string {
+	context.AddError(errors.New("TABLESAMPLE is not supported by this compiler"))
+	return ""
+}
+
+// VisitText return a raw SQL clause as is, or with its placeholders replaced
+// by the dialect ones if it has binds
 func (SQLCompiler) VisitText(context *CompilerContext, text TextClause) string {
-	return text.Text
+	if len(text.Binds) == 0 {
+		return text.Text
+	}
+	parts := strings.Split(text.Text, "?")
+	if len(parts)-1 != len(text.Binds) {
+		context.AddError(fmt.Errorf(
+			"SQL text has %d placeholders for %d binds: %s",
+			len(parts)-1, len(text.Binds), text.Text))
+		return text.Text
+	}
+	sql := parts[0]
+	for i, value := range text.Binds {
+		sql += Bind(value).Accept(context) + parts[i+1]
+	}
+	return sql
+}
+
+// VisitUnary compiles a prefix operator expression. The operand is
+// parenthesized unless it is a column or a bound value, so the operator
+// applies to the whole expression and two '-' do not start a comment
+func (SQLCompiler) VisitUnary(context *CompilerContext, unary UnaryClause) string {
+	switch unary.Clause.(type) {
+	case ColumnElem, BindClause, NamedBindClause, AliasRefClause:
+		return unary.Op + unary.Clause.Accept(context)
+	}
+	return unary.Op + "(" + unary.Clause.Accept(context) + ")"
 }
 
 // VisitUpdate compiles a UPDATE statement
+// The SET assignments are sorted by column name, and the binds follow the
+// SET, FROM and WHERE order
 func (c SQLCompiler) VisitUpdate(context *CompilerContext, update UpdateStmt) string {
 	context.DefaultTableName = update.table.Name
 	defer func() { context.DefaultTableName = "" }()
 
-	sql := "UPDATE " + update.table.Accept(context)
+	if context.Safe && update.where == nil && !update.unsafe {
+		context.AddError(errors.New("Update without WHERE clause, use Unsafe() to update all the rows"))
+	}
+	if update.orderBy != nil || update.count != nil {
+		context.AddError(errors.New("UPDATE ... ORDER BY and LIMIT are not supported by this compiler"))
+	}
+
+	sql := ""
+	if len(update.with.CTEs) > 0 {
+		defer saveCTEs(context)()
+		sql = update.with.Accept(context) + "\n"
+	}
+
+	sql += "UPDATE " + update.table.Accept(context)
 
-	sets := List()
+	// the assignments are not comparisons, they are compiled apart from
+	// VisitBinary so a nil value is not turned into IS NULL
+	sets := []string{}
+	for _, k := range sortedKeys(update.values) {
+		sets = append(sets, fmt.Sprintf(
+			"%s = %s",
+			context.Compiler.VisitLabel(context, k),
+			compileNamed(context, k, GetClauseFrom(update.values[k])),
+		))
+	}
 
-	for k, v := range update.values {
-		sets.Clauses = append(sets.Clauses,
-			Eq(update.table.C(k), Bind(v)))
+	if len(sets) > 0 {
+		sql += "\nSET " + strings.Join(sets, ", ")
 	}
 
-	if len(sets.Clauses) > 0 {
-		sql += "\nSET " + sets.Accept(context)
+	if update.from != nil {
+		// the columns of the updated table are qualified past the SET
+		// clause, they may be ambiguous with the FROM ones
+		context.DefaultTableName = ""
+		sql += "\nFROM " + update.from.Accept(context)
 	}
 
 	if update.where != nil {
 		sql += "\n" + update.where.Accept(context)
 	}
 
-	returning := []string{}
-	for _, c := range update.returning {
-		returning = append(returning, context.Dialect.Escape(c.Name))
-	}
-
-	if len(returning) > 0 {
-		sql += "\nRETURNING " + strings.Join(returning, ", ")
+	if update.from != nil {
+		sql += compileReturning(context, "", update.returning)
+	} else {
+		sql += compileReturning(context, update.table.Name, update.returning)
 	}
 
 	return sql
 }
 
-// VisitUpsert is not implemented and will panic.
+// VisitUpsert is not implemented and will raise an error.
 // It should be implemented in each dialect
 func (c SQLCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) string {
-	panic("Upsert is not Implemented in this compiler")
+	context.AddError(errors.New("Upsert is not Implemented in this compiler"))
+	return ""
+}
+
+// VisitValues compiles a '(VALUES (...), (...))' list of rows
+func (SQLCompiler) VisitValues(context *CompilerContext, values ValuesClause) string {
+	return compileValues(context, values, "")
+}
+
+// compileValues compiles the rows of a values list, each one prefixed with
+// rowPrefix
+func compileValues(context *CompilerContext, values ValuesClause, rowPrefix string) string {
+	if len(values.Rows) == 0 {
+		context.AddError(errors.New("VALUES list has no row"))
+	}
+	rows := []string{}
+	for i, row := range values.Rows {
+		if len(row) != len(values.Columns) {
+			context.AddError(fmt.Errorf(
+				"VALUES row %d has %d values, expected %d", i+1, len(row), len(values.Columns)))
+		}
+		compiled := []string{}
+		for _, clause := range row {
+			compiled = append(compiled, clause.Accept(context))
+		}
+		rows = append(rows, fmt.Sprintf("%s(%s)", rowPrefix, strings.Join(compiled, ", ")))
+	}
+	return fmt.Sprintf("(VALUES %s)", strings.Join(rows, ", "))
+}
+
+// VisitWindow compiles a window function call
+func (c SQLCompiler) VisitWindow(context *CompilerContext, window WindowClause) string {
+	parts := []string{}
+	if len(window.partitionBy) > 0 {
+		partitionBy := []string{}
+		for _, clause := range window.partitionBy {
+			partitionBy = append(partitionBy, clause.Accept(context))
+		}
+		parts = append(parts, fmt.Sprintf("PARTITION BY %s", strings.Join(partitionBy, ", ")))
+	}
+	if window.orderBy != nil {
+		parts = append(parts, window.orderBy.Accept(context))
+	}
+	if window.frame != nil && window.frame.Mode == "" {
+		context.AddError(fmt.Errorf(
+			"EXCLUDE %s needs a window frame, use Rows() or Range()", window.frame.Exclude))
+	} else if window.frame != nil {
+		frame := fmt.Sprintf("%s BETWEEN %s AND %s", window.frame.Mode, window.frame.Start, window.frame.End)
+		if window.frame.Exclude != "" {
+			frame += " EXCLUDE " + window.frame.Exclude
+		}
+		parts = append(parts, frame)
+	}
+	nulls := ""
+	if window.nulls != "" {
+		nulls = " " + window.nulls
+	}
+	return fmt.Sprintf("%s%s OVER (%s)", window.clause.Accept(context), nulls, strings.Join(parts, " "))
 }
 
 // VisitWhere compiles a WHERE clause
 func (c SQLCompiler) VisitWhere(context *CompilerContext, where WhereClause) string {
 	return fmt.Sprintf("WHERE %s", where.clause.Accept(context))
 }
+
+// saveCTEs returns a function that restores the CTE names declared in the
+// context, to scope the CTEs of a WITH clause to its statement
+func saveCTEs(context *CompilerContext) func() {
+	ctes, ok := context.Vars[varCTEs]
+	return func() {
+		if ok {
+			context.Vars[varCTEs] = ctes
+		} else {
+			delete(context.Vars, varCTEs)
+		}
+	}
+}
+
+// VisitWith compiles a WITH clause
+// The data-modifying CTEs are not ANSI, the dialects that support them must
+// override this function
+func (c SQLCompiler) VisitWith(context *CompilerContext, with WithClause) string {
+	for _, cte := range with.CTEs {
+		switch cte.Statement.(type) {
+		case DeleteStmt, UpdateStmt, InsertStmt:
+			context.AddError(fmt.Errorf("Data-modifying CTE %s is not supported by this compiler", cte.Name))
+		}
+	}
+	return compileWith(context, with)
+}
+
+// compileWith compiles a WITH clause
+// The CTE statements are compiled in their declaration order, so a CTE can
+// refer to the ones declared before it, and a recursive CTE to itself. The
+// CTE names are added to the varCTEs var, the statement restores it once
+// compiled.
+func compileWith(context *CompilerContext, with WithClause) string {
+	defaultTableName := context.DefaultTableName
+	defer func() { context.DefaultTableName = defaultTableName }()
+
+	names, _ := context.Vars[varCTEs].([]string)
+	ctes := []string{}
+	recursive := false
+	for _, cte := range with.CTEs {
+		if cte.Recursive {
+			recursive = true
+			names = append(names[:len(names):len(names)], cte.Name)
+			context.Vars[varCTEs] = names
+		}
+		name := context.Compiler.VisitLabel(context, cte.Name)
+		if len(cte.Columns) > 0 {
+			columns := []string{}
+			for _, column := range cte.Columns {
+				columns = append(columns, context.Compiler.VisitLabel(context, column))
+			}
+			name += fmt.Sprintf("(%s)", strings.Join(columns, ", "))
+		}
+		ctes = append(ctes, fmt.Sprintf("%s AS (%s)", name, cte.body().Accept(context)))
+		if !cte.Recursive {
+			names = append(names[:len(names):len(names)], cte.Name)
+			context.Vars[varCTEs] = names
+		}
+	}
+	if recursive {
+		return fmt.Sprintf("WITH RECURSIVE %s", strings.Join(ctes, ", "))
+	}
+	return fmt.Sprintf("WITH %s", strings.Join(ctes, ", "))
+}
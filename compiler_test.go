@@ -1,6 +1,7 @@
 package qb
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -73,3 +74,150 @@ func TestCompile(t *testing.T) {
 		assert.Equal(t, tt.binds, binds)
 	}
 }
+
+func TestCustomPlaceholder(t *testing.T) {
+	placeholder := func(index int) string {
+		return fmt.Sprintf("{%d}", index)
+	}
+	sel := Select(TTUser.C("id")).From(TTUser).
+		Where(And(TTUser.C("name").Eq("me"), TTUser.C("main_group_id").Eq(2)))
+
+	for _, dialect := range []string{"default", "postgres"} {
+		context := NewCompilerContext(NewDialect(dialect))
+		context.Placeholder = placeholder
+		statement, err := BuildStmt(context, sel)
+		assert.Nil(t, err)
+		assert.Contains(t, statement.SQL(), "= {1} AND ")
+		assert.Contains(t, statement.SQL(), "= {2});")
+		assert.Equal(t, []interface{}{"me", 2}, statement.Bindings())
+	}
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.Placeholder = placeholder
+	statement, err := BuildStmt(context, Upsert(TTGroup).Values(map[string]interface{}{"id": 1}))
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO group(id)\nVALUES({1})\nON CONFLICT (id) DO UPDATE SET id = {2};", statement.SQL())
+}
+
+func TestCompileFragment(t *testing.T) {
+	sql, binds := Compile(TTUser.C("name").Eq("me"), NewDialect("postgres"))
+	assert.Equal(t, "user.name = $1", sql)
+	assert.Equal(t, []interface{}{"me"}, binds)
+
+	sql, binds = Compile(And(TTUser.C("id").Gt(1), TTUser.C("id").Lt(5)), NewDialect("mysql"))
+	assert.Equal(t, "(user.id > ? AND user.id < ?)", sql)
+	assert.Equal(t, []interface{}{1, 5}, binds)
+}
+
+func TestVarsDeterministic(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("age", Int()),
+	)
+	adults := With("adults", Select(users.C("id"), users.C("email")).From(users).Where(users.C("age").Gte(18)))
+	sel := Select(adults.C("email")).
+		From(adults).
+		Where(adults.C("id").In(1, 2, 3), Eq(adults.C("email"), "joe@example.com")).
+		With(adults)
+
+	build := func() *Stmt {
+		context := NewCompilerContext(NewDialect("postgres"))
+		context.Strict = true
+		statement, err := BuildStmt(context, sel)
+		assert.Nil(t, err)
+		return statement
+	}
+	first := build()
+	for i := 0; i < 10; i++ {
+		statement := build()
+		assert.Equal(t, first.SQL(), statement.SQL())
+		assert.Equal(t, first.Bindings(), statement.Bindings())
+		assert.Equal(t, first.NamedBindings(), statement.NamedBindings())
+	}
+	assert.Equal(t, map[string]interface{}{
+		"age": 18, "id": 1, "id_2": 2, "id_3": 3, "email": "joe@example.com",
+	}, first.NamedBindings())
+}
+
+func TestStableOutput(t *testing.T) {
+	orders := Table(
+		"orders",
+		Column("id", Int()),
+		Column("user_id", Int()),
+		Column("region", Varchar()),
+		Column("status", Varchar()),
+		Column("amount", Int()),
+		Column("note", Varchar()),
+		Column("created_at", Timestamp()),
+	)
+	postgres := NewDialect("postgres")
+
+	insert := Insert(orders).Values(map[string]interface{}{
+		"id": 1, "user_id": 2, "region": "north", "status": "paid",
+		"amount": 30, "note": "gift", "created_at": "2020-01-01",
+	})
+	total := As(Sum(orders.C("amount")), "total")
+	sel := Select(orders.C("region"), total).
+		From(orders).
+		Where(orders.C("status").In("paid", "shipped")).
+		GroupBy(orders.C("region")).
+		Having(Sum(orders.C("amount")), ">", 100).
+		OrderBy(total.Ref()).Desc()
+	update := Update(orders).
+		Values(map[string]interface{}{"status": "cancelled", "note": "late", "amount": 0}).
+		Where(orders.C("id").Eq(1))
+
+	firstInsert := insert.Build(postgres).SQL()
+	firstSelect := sel.Build(postgres).SQL()
+	firstUpdate := update.Build(postgres).SQL()
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, firstInsert, insert.Build(postgres).SQL())
+		assert.Equal(t, firstSelect, sel.Build(postgres).SQL())
+		assert.Equal(t, firstUpdate, update.Build(postgres).SQL())
+	}
+
+	// deriving statements leaves the original ones untouched
+	sel.Asc().Having(Count(orders.C("id")), ">", 1)
+	insert.Values(map[string]interface{}{"region": "south"})
+	update.Values(map[string]interface{}{"region": "south"})
+	assert.Equal(t, firstSelect, sel.Build(postgres).SQL())
+	assert.Equal(t, firstInsert, insert.Build(postgres).SQL())
+	assert.Equal(t, firstUpdate, update.Build(postgres).SQL())
+}
+
+func TestStrictReservedWords(t *testing.T) {
+	items := Table("items", Column("id", Int()), Column("order", Int()), Column("Select", Varchar()))
+	sel := Select(items.C("id"), items.C("order")).
+		From(items).
+		Where(items.C("Select").Eq("x")).
+		OrderBy(items.C("order"))
+
+	strict := func(dialect Dialect) string {
+		context := NewCompilerContext(dialect)
+		context.Strict = true
+		statement, err := BuildStmt(context, sel)
+		assert.Nil(t, err)
+		return statement.SQL()
+	}
+
+	assert.Equal(t, "SELECT id, \"order\"\nFROM items\nWHERE \"Select\" = $1\nORDER BY \"order\" ASC;", strict(NewDialect("postgres")))
+	assert.Equal(t, "SELECT id, `order`\nFROM items\nWHERE `Select` = ?\nORDER BY `order` ASC;", strict(NewDialect("mysql")))
+	assert.Equal(t, "SELECT id, \"order\"\nFROM items\nWHERE \"Select\" = ?\nORDER BY \"order\" ASC;", strict(NewDialect("sqlite3")))
+	assert.Equal(t, "SELECT id, \"order\"\nFROM items\nWHERE \"Select\" = ?\nORDER BY \"order\" ASC;", strict(NewDialect("default")))
+
+	// the escaping dialects are left as is
+	postgres := NewDialect("postgres")
+	postgres.SetEscaping(true)
+	assert.Equal(t, "SELECT \"id\", \"order\"\nFROM \"items\"\nWHERE \"Select\" = $1\nORDER BY \"order\" ASC;", strict(postgres))
+
+	// not in strict mode
+	assert.Equal(t, "SELECT id, order\nFROM items\nWHERE Select = $1\nORDER BY order ASC;", sel.Build(NewDialect("postgres")).SQL())
+
+	// a reserved word of a dialect only
+	settings := Table("settings", Column("key", Varchar()))
+	sel = Select(settings.C("key")).From(settings)
+	assert.Equal(t, "SELECT `key`\nFROM settings;", strict(NewDialect("mysql")))
+	assert.Equal(t, "SELECT key\nFROM settings;", strict(NewDialect("postgres")))
+}
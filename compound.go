@@ -0,0 +1,133 @@
+package qb
+
+// Union generates a compound statement of the distinct rows of the select
+// statements
+func Union(selects ...SelectStmt) CompoundStmt {
+	return compound("UNION", selects)
+}
+
+// UnionAll generates a compound statement of all the rows of the select
+// statements
+func UnionAll(selects ...SelectStmt) CompoundStmt {
+	return compound("UNION ALL", selects)
+}
+
+// Intersect generates a compound statement of the rows that all the select
+// statements return
+func Intersect(selects ...SelectStmt) CompoundStmt {
+	return compound("INTERSECT", selects)
+}
+
+// Except generates a compound statement of the rows of the first select
+// statement that the others do not return
+func Except(selects ...SelectStmt) CompoundStmt {
+	return compound("EXCEPT", selects)
+}
+
+func compound(operator string, selects []SelectStmt) CompoundStmt {
+	return CompoundStmt{
+		operator: operator,
+		selects:  selects,
+	}
+}
+
+// CompoundStmt is a set operation between select statements, like
+// (SELECT ...) UNION (SELECT ...), with an optional ORDER BY and LIMIT that
+// apply to the whole result
+type CompoundStmt struct {
+	with     WithClause
+	operator string
+	selects  []SelectStmt
+	orderBy  *OrderByClause
+	offset   *int
+	count    *int
+}
+
+// With appends common table expressions to the statement, that all the
+// members can use
+func (s CompoundStmt) With(ctes ...CTEClause) CompoundStmt {
+	s.with.CTEs = append(append([]CTEClause{}, s.with.CTEs...), ctes...)
+	return s
+}
+
+// OrderBy sets the order of the compound statement rows. The columns must
+// be the ones of the first select statement, or references to the result
+// columns with AliasRef()
+func (s CompoundStmt) OrderBy(columns ...Clause) CompoundStmt {
+	s.orderBy = &OrderByClause{columns, "ASC"}
+	return s
+}
+
+// Asc sets the t type of current order by clause
+// NOTE: Please use it after calling OrderBy()
+func (s CompoundStmt) Asc() CompoundStmt {
+	orderBy := *s.orderBy
+	orderBy.t = "ASC"
+	s.orderBy = &orderBy
+	return s
+}
+
+// Desc sets the t type of current order by clause
+// NOTE: Please use it after calling OrderBy()
+func (s CompoundStmt) Desc() CompoundStmt {
+	orderBy := *s.orderBy
+	orderBy.t = "DESC"
+	s.orderBy = &orderBy
+	return s
+}
+
+// Limit sets the offset & count values of the compound statement
+func (s CompoundStmt) Limit(offset int, count int) CompoundStmt {
+	s.offset = &offset
+	s.count = &count
+	return s
+}
+
+// LimitCount sets the count value of the compound statement only, to limit
+// the number of rows without an offset
+func (s CompoundStmt) LimitCount(count int) CompoundStmt {
+	s.count = &count
+	return s
+}
+
+// Offset sets the offset value of the compound statement only, to skip rows
+// without limiting their number
+func (s CompoundStmt) Offset(offset int) CompoundStmt {
+	s.offset = &offset
+	return s
+}
+
+// Accept calls the compiler VisitCompound function
+func (s CompoundStmt) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitCompound(context, s)
+}
+
+// Build generates a statement out of CompoundStmt object
+// It panics if the statement cannot be compiled
+func (s CompoundStmt) Build(dialect Dialect) *Stmt {
+	return mustBuildStmt(dialect, s)
+}
+
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s CompoundStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
+
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s CompoundStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s CompoundStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
+
+// String compiles the statement with the default dialect, for inspection
+func (s CompoundStmt) String() string {
+	return stmtString(s)
+}
@@ -0,0 +1,111 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompound(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()), Column("active", Boolean()))
+	admins := Table("admins", Column("id", Int()), Column("email", Varchar()))
+
+	activeUsers := Select(users.C("id"), users.C("email")).From(users).Where(users.C("active").Eq(true))
+	someAdmins := Select(admins.C("id"), admins.C("email")).From(admins).Where(admins.C("id").Lt(10))
+
+	statement := Union(activeUsers, someAdmins).Build(NewDialect("postgres"))
+	assert.Equal(t, "(SELECT id, email\nFROM users\nWHERE active = $1)\nUNION\n(SELECT id, email\nFROM admins\nWHERE id < $2);", statement.SQL())
+	assert.Equal(t, []interface{}{true, 10}, statement.Bindings())
+
+	others := Select(admins.C("id"), admins.C("email")).From(admins).Where(admins.C("email").Like("%@example.com"))
+	statement = UnionAll(activeUsers, someAdmins, others).
+		OrderBy(users.C("email")).Desc().
+		Limit(0, 20).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, `(SELECT id, email
+FROM users
+WHERE active = $1)
+UNION ALL
+(SELECT id, email
+FROM admins
+WHERE id < $2)
+UNION ALL
+(SELECT id, email
+FROM admins
+WHERE email LIKE $3)
+ORDER BY email DESC
+LIMIT 20 OFFSET 0;`, statement.SQL())
+	assert.Equal(t, []interface{}{true, 10, "%@example.com"}, statement.Bindings())
+
+	assert.Equal(t, "(SELECT id\nFROM users)\nINTERSECT\n(SELECT id\nFROM admins)", asDefSQL(Intersect(Select(users.C("id")).From(users), Select(admins.C("id")).From(admins))))
+	assert.Equal(t, "(SELECT id\nFROM users)\nEXCEPT\n(SELECT id\nFROM admins)", asDefSQL(Except(Select(users.C("id")).From(users), Select(admins.C("id")).From(admins))))
+
+	// sqlite has no parenthesized members
+	statement = Union(activeUsers, someAdmins).OrderBy(AliasRef("email")).Build(NewDialect("sqlite3"))
+	assert.Equal(t, "SELECT id, email\nFROM users\nWHERE active = ?\nUNION\nSELECT id, email\nFROM admins\nWHERE id < ?\nORDER BY email ASC;", statement.SQL())
+	_, _, err := Union(activeUsers.LimitCount(5), someAdmins).ToSQL(NewDialect("sqlite3"))
+	assert.EqualError(t, err, "UNION members cannot have an ORDER BY or a LIMIT in sqlite")
+
+	_, _, err = Union(activeUsers).ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "UNION needs at least 2 select statements")
+}
+
+func TestCompoundLimit(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+	admins := Table("admins", Column("id", Int()), Column("email", Varchar()))
+
+	union := Union(
+		Select(users.C("email")).From(users).Where(users.C("id").Gt(5)),
+		Select(admins.C("email")).From(admins),
+	).OrderBy(AliasRef("email")).LimitCount(10)
+
+	sql, binds := asSQLBinds(union, NewDialect("postgres"))
+	assert.Equal(t, "(SELECT email\nFROM users\nWHERE id > $1)\nUNION\n(SELECT email\nFROM admins)\nORDER BY email ASC\nLIMIT 10", sql)
+	assert.Equal(t, []interface{}{5}, binds)
+
+	sql, _ = asSQLBinds(union.Offset(20), NewDialect("mysql"))
+	assert.Equal(t, "(SELECT email\nFROM users\nWHERE id > ?)\nUNION\n(SELECT email\nFROM admins)\nORDER BY email ASC\nLIMIT 10 OFFSET 20", sql)
+
+	sql, _ = asSQLBinds(union.Offset(20), NewDialect("sqlite3"))
+	assert.Equal(t, "SELECT email\nFROM users\nWHERE id > ?\nUNION\nSELECT email\nFROM admins\nORDER BY email ASC\nLIMIT 10 OFFSET 20", sql)
+}
+
+func TestCompoundDedupBinds(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()), Column("tags", Varchar()))
+	admins := Table("admins", Column("id", Int()), Column("email", Varchar()))
+
+	union := Union(
+		Select(users.C("id")).From(users).Where(users.C("email").Like("%@example.com"), users.C("id").Gt(10)),
+		Select(admins.C("id")).From(admins).Where(admins.C("email").Like("%@example.com"), admins.C("id").Gt(int64(10))),
+	)
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.DedupBinds = true
+	context.CheckBinds = true
+	statement, err := BuildStmt(context, union)
+	assert.Nil(t, err)
+	assert.Equal(t, "(SELECT id\nFROM users\nWHERE (email LIKE $1 AND id > $2))\n"+
+		"UNION\n"+
+		"(SELECT id\nFROM admins\nWHERE (email LIKE $1 AND id > $3));", statement.SQL())
+	// 10 and int64(10) are distinct values
+	assert.Equal(t, []interface{}{"%@example.com", 10, int64(10)}, statement.Bindings())
+
+	// the values that are not comparable are not collapsed
+	tags := []byte("a")
+	context = NewCompilerContext(NewDialect("postgres"))
+	context.DedupBinds = true
+	statement, err = BuildStmt(context, Union(
+		Select(users.C("id")).From(users).Where(users.C("tags").Eq(tags)),
+		Select(users.C("id")).From(users).Where(users.C("tags").Eq(tags)),
+	))
+	assert.Nil(t, err)
+	assert.Contains(t, statement.SQL(), "tags = $2")
+	assert.Len(t, statement.Bindings(), 2)
+
+	// without the option, or with a dialect without numbered placeholders,
+	// every value is bound
+	assert.Len(t, union.Build(NewDialect("postgres")).Bindings(), 4)
+	context = NewCompilerContext(NewDialect("mysql"))
+	context.DedupBinds = true
+	statement, _ = BuildStmt(context, union)
+	assert.Len(t, statement.Bindings(), 4)
+}
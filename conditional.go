@@ -7,6 +7,17 @@ func Like(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "LIKE", GetClauseFrom(right))
 }
 
+// LikeAny generates a conditional sql clause that matches any of the patterns
+func LikeAny(left Clause, patterns ...string) LikeAnyClause {
+	return LikeAnyClause{Left: left, Patterns: patterns}
+}
+
+// ILikeAny generates a case insensitive conditional sql clause that matches
+// any of the patterns
+func ILikeAny(left Clause, patterns ...string) LikeAnyClause {
+	return LikeAnyClause{Left: left, Patterns: patterns, CaseInsensitive: true}
+}
+
 // In generates an IN conditional sql clause
 func In(left Clause, values ...interface{}) InClause {
 	return InClause{BinaryExpressionClause{
@@ -25,6 +36,15 @@ func NotIn(left Clause, values ...interface{}) InClause {
 	}}
 }
 
+// InTopN generates an IN conditional sql clause on a subquery, typically
+// selecting the top N rows with ORDER BY and LIMIT or from a ranking:
+// InTopN(users.C("id"), Select(ranked.C("id")).From(ranked).Where(ranked.C("rn").Lte(3)))
+// The subquery must select exactly one column
+// NOTE: mysql does not support a LIMIT in the subquery
+func InTopN(left Clause, sel SelectStmt) InClause {
+	return In(left, sel)
+}
+
 // NotEq generates a not equal conditional sql clause
 func NotEq(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "!=", GetClauseFrom(right))
@@ -35,6 +55,16 @@ func Eq(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "=", GetClauseFrom(right))
 }
 
+// IsDistinctFrom generates a null-safe not equal conditional sql clause
+func IsDistinctFrom(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "IS DISTINCT FROM", GetClauseFrom(right))
+}
+
+// IsNotDistinctFrom generates a null-safe equals conditional sql clause
+func IsNotDistinctFrom(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "IS NOT DISTINCT FROM", GetClauseFrom(right))
+}
+
 // Gt generates a greater than conditional sql clause
 func Gt(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, ">", GetClauseFrom(right))
@@ -55,6 +85,22 @@ func Lte(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "<=", GetClauseFrom(right))
 }
 
+// Between generates a BETWEEN conditional sql clause
+func Between(left Clause, lower interface{}, upper interface{}) BetweenClause {
+	return BetweenClause{
+		Left:  left,
+		Lower: GetClauseFrom(lower),
+		Upper: GetClauseFrom(upper),
+	}
+}
+
+// NotBetween generates a NOT BETWEEN conditional sql clause
+func NotBetween(left Clause, lower interface{}, upper interface{}) BetweenClause {
+	between := Between(left, lower, upper)
+	between.Not = true
+	return between
+}
+
 // BinaryExpression generates a condition object to use in update, delete & select statements
 func BinaryExpression(left Clause, op string, right Clause) BinaryExpressionClause {
 	return BinaryExpressionClause{
@@ -85,3 +131,30 @@ type InClause struct {
 func (c InClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitIn(context, c)
 }
+
+// BetweenClause is a <left> (NOT) BETWEEN <lower> AND <upper> expression
+type BetweenClause struct {
+	Left  Clause
+	Lower Clause
+	Upper Clause
+	Not   bool
+}
+
+// Accept calls the compiler VisitBetween method
+func (c BetweenClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitBetween(context, c)
+}
+
+// LikeAnyClause matches an expression against a list of patterns
+// It compiles to LIKE ANY (ARRAY[...]) on postgres, and to ORed LIKE on the
+// other dialects
+type LikeAnyClause struct {
+	Left            Clause
+	Patterns        []string
+	CaseInsensitive bool
+}
+
+// Accept calls the compiler VisitLikeAny method
+func (c LikeAnyClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitLikeAny(context, c)
+}
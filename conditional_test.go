@@ -1,7 +1,11 @@
 package qb
 
 import (
+	"database/sql"
+	"encoding/json"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"net"
 	"testing"
 )
 
@@ -157,3 +161,343 @@ func TestConditionals(t *testing.T) {
 	assert.Equal(t, []interface{}{1500}, bindings)
 
 }
+
+func TestScalarSubqueryComparison(t *testing.T) {
+	emp := Table(
+		"emp",
+		Column("id", Int()).PrimaryKey(),
+		Column("dept", Varchar()),
+		Column("salary", Int()),
+	)
+	avgSalary := Select(Avg(emp.C("salary"))).From(emp).Where(emp.C("dept").Eq("sales"))
+
+	sel := Select(emp.C("id")).
+		From(emp).
+		Where(And(
+			emp.C("dept").Eq("sales"),
+			emp.C("salary").Gt(avgSalary),
+		))
+
+	statement := sel.Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM emp\nWHERE (dept = $1 AND salary > (SELECT AVG(emp.salary)\nFROM emp\nWHERE emp.dept = $2));", statement.SQL())
+	assert.Equal(t, []interface{}{"sales", "sales"}, statement.Bindings())
+
+	// IN takes the select as its list
+	assert.Equal(t,
+		"emp.dept IN (SELECT emp.dept\nFROM emp)",
+		asDefSQL(In(emp.C("dept"), Select(emp.C("dept")).From(emp))))
+
+	// an explicit Subquery is the same
+	assert.Equal(t,
+		asDefSQL(Gt(emp.C("salary"), avgSalary)),
+		asDefSQL(Gt(emp.C("salary"), Subquery(avgSalary))))
+}
+
+func TestInTopN(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+	ranked := Table("ranked", Column("id", Int()), Column("rn", Int()))
+
+	sel := Select(users.C("email")).
+		From(users).
+		Where(users.C("id").InTopN(Select(ranked.C("id")).From(ranked).Where(ranked.C("rn").Lte(3))))
+	statement := sel.Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT email\nFROM users\nWHERE id IN (SELECT ranked.id\nFROM ranked\nWHERE ranked.rn <= $1);", statement.SQL())
+	assert.Equal(t, []interface{}{3}, statement.Bindings())
+
+	_, _, err := Select(users.C("email")).
+		From(users).
+		Where(users.C("id").InTopN(Select(ranked.C("id"), ranked.C("rn")).From(ranked))).
+		ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "IN subquery must select exactly one column, it selects 2")
+
+	top := Select(users.C("email")).
+		From(users).
+		Where(users.C("id").InTopN(Select(ranked.C("id")).From(ranked).OrderBy(ranked.C("rn")).LimitCount(3)))
+	_, _, err = top.ToSQL(NewDialect("mysql"))
+	assert.EqualError(t, err, "LIMIT in a IN subquery is not supported by mysql")
+	_, _, err = top.ToSQL(NewDialect("sqlite3"))
+	assert.NoError(t, err)
+}
+
+func TestLikeAny(t *testing.T) {
+	name := Column("name", Varchar())
+
+	sql, binds := asSQLBinds(ILikeAny(name, "%al%", "%bob%"), NewDialect("postgres"))
+	assert.Equal(t, "name ILIKE ANY (ARRAY[$1, $2])", sql)
+	assert.Equal(t, []interface{}{"%al%", "%bob%"}, binds)
+
+	sql, _ = asSQLBinds(name.LikeAny("%al%", "%bob%"), NewDialect("postgres"))
+	assert.Equal(t, "name LIKE ANY (ARRAY[$1, $2])", sql)
+
+	sql, binds = asSQLBinds(name.ILikeAny("%al%", "%bob%"), NewDialect("sqlite3"))
+	assert.Equal(t, "(LOWER(name) LIKE LOWER(?) OR LOWER(name) LIKE LOWER(?))", sql)
+	assert.Equal(t, []interface{}{"%al%", "%bob%"}, binds)
+
+	sql, _ = asSQLBinds(LikeAny(name, "%al%", "%bob%"), NewDialect("mysql"))
+	assert.Equal(t, "(name LIKE ? OR name LIKE ?)", sql)
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("postgres")), Select(name).Where(ILikeAny(name)))
+	assert.EqualError(t, err, "LIKE ANY needs at least one pattern")
+}
+
+func TestInSubquery(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("name", Varchar()),
+	)
+	orders := Table(
+		"orders",
+		Column("id", Int()),
+		Column("user_id", Int()),
+		Column("status", Varchar()),
+		Column("total", Int()),
+	)
+
+	topBuyers := Select(orders.C("user_id")).
+		From(orders).
+		Where(orders.C("status").Eq("paid")).
+		OrderBy(orders.C("total")).Desc().
+		Limit(0, 10)
+
+	sel := Select(users.C("name")).
+		From(users).
+		Where(users.C("id").In(topBuyers), users.C("name").NotEq("root")).
+		OrderBy(users.C("name"))
+
+	statement := sel.Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT name\nFROM users\nWHERE (id IN (SELECT orders.user_id\nFROM orders\nWHERE orders.status = $1\nORDER BY orders.total DESC\nLIMIT 10 OFFSET 0) AND name != $2)\nORDER BY name ASC;", statement.SQL())
+	assert.Equal(t, []interface{}{"paid", "root"}, statement.Bindings())
+}
+
+func TestNullComparisons(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+	where := Where(users.C("email").Eq(nil), users.C("id").NotEq(nil), users.C("id").Gt(2))
+
+	sql, binds := asDefSQLBinds(where)
+	assert.Equal(t, "WHERE (users.email = ? AND users.id != ? AND users.id > ?)", sql)
+	assert.Equal(t, []interface{}{nil, nil, 2}, binds)
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.NullComparisons = true
+	statement, err := BuildStmt(context, Select(users.C("id")).From(users).Where(
+		users.C("email").Eq(nil), users.C("id").NotEq(nil), users.C("id").Gt(2), users.C("email").Eq("x")))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE (email IS NULL AND id IS NOT NULL AND id > $1 AND email = $2);", statement.SQL())
+	assert.Equal(t, []interface{}{2, "x"}, statement.Bindings())
+
+	// a nil SET value is an assignment, not a comparison
+	context = NewCompilerContext(NewDialect("postgres"))
+	context.NullComparisons = true
+	statement, err = BuildStmt(context, Update(users).Values(map[string]interface{}{"email": nil}).Where(users.C("email").Eq(nil)))
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE users\nSET email = $1\nWHERE email IS NULL;", statement.SQL())
+	assert.Equal(t, []interface{}{nil}, statement.Bindings())
+}
+
+func TestArrayIn(t *testing.T) {
+	users := Table("users", Column("id", Int()))
+	ids := []int{1, 2, 3}
+	context := func() *CompilerContext {
+		context := NewCompilerContext(NewDialect("postgres"))
+		context.ArrayIn = true
+		return context
+	}
+
+	statement, err := BuildStmt(context(), Select(users.C("id")).From(users).Where(users.C("id").In(ids)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE id = ANY($1);", statement.SQL())
+	assert.Equal(t, []interface{}{ids}, statement.Bindings())
+
+	statement, err = BuildStmt(context(), Select(users.C("id")).From(users).Where(users.C("id").NotIn(ids)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE id != ALL($1);", statement.SQL())
+
+	// the lists of values are still expanded
+	statement, err = BuildStmt(context(), Select(users.C("id")).From(users).Where(users.C("id").In(1, 2)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE id IN ($1, $2);", statement.SQL())
+	statement, err = BuildStmt(context(), Select(users.C("id")).From(users).Where(users.C("id").In(1)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE id IN ($1);", statement.SQL())
+
+	// the pq.Array() values are arrays
+	for _, array := range []interface{}{pq.Array([]int64{1, 2}), pq.Array([]int{1, 2}), pq.Int64Array{1, 2}} {
+		statement, err = BuildStmt(context(), Select(users.C("id")).From(users).Where(users.C("id").In(array)))
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT id\nFROM users\nWHERE id = ANY($1);", statement.SQL())
+		assert.Equal(t, []interface{}{array}, statement.Bindings())
+	}
+
+	// a driver value that is not a slice is a single value
+	id := sql.NullInt64{Int64: 4, Valid: true}
+	statement, err = BuildStmt(context(), Select(users.C("id")).From(users).Where(users.C("id").In(id)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE id IN ($1);", statement.SQL())
+	assert.Equal(t, []interface{}{id}, statement.Bindings())
+	name := sql.NullString{String: "joe", Valid: true}
+	statement, err = BuildStmt(context(), Select(users.C("id")).From(users).Where(users.C("id").NotIn(name)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE id NOT IN ($1);", statement.SQL())
+}
+
+func TestInValues(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("name", Varchar()))
+
+	sql, binds := asDefSQLBinds(users.C("id").In([]int{1, 2, 3}))
+	assert.Equal(t, "users.id IN (?, ?, ?)", sql)
+	assert.Equal(t, []interface{}{1, 2, 3}, binds)
+
+	sql, binds = asDefSQLBinds(users.C("name").NotIn([]string{"root", "admin"}))
+	assert.Equal(t, "users.name NOT IN (?, ?)", sql)
+	assert.Equal(t, []interface{}{"root", "admin"}, binds)
+
+	// IN () is not valid SQL
+	assert.Equal(t, "1 = 0", asDefSQL(users.C("id").In()))
+	assert.Equal(t, "1 = 0", asDefSQL(users.C("id").In([]int{})))
+	assert.Equal(t, "1 = 1", asDefSQL(users.C("id").NotIn([]int{})))
+
+	statement := Select(users.C("name")).From(users).Where(users.C("id").In([]int{}), users.C("name").Eq("x")).Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT name\nFROM users\nWHERE (1 = 0 AND name = $1);", statement.SQL())
+	assert.Equal(t, []interface{}{"x"}, statement.Bindings())
+
+	// a []byte is a single value
+	sql, binds = asDefSQLBinds(users.C("name").In([]byte("x")))
+	assert.Equal(t, "users.name IN (?)", sql)
+	assert.Equal(t, []interface{}{[]byte("x")}, binds)
+
+	// so are the other slices of bytes, and the driver values
+	raw := json.RawMessage(`{"a":1}`)
+	sql, binds = asDefSQLBinds(users.C("name").In(raw))
+	assert.Equal(t, "users.name IN (?)", sql)
+	assert.Equal(t, []interface{}{raw}, binds)
+	ip := net.ParseIP("10.0.0.1")
+	sql, binds = asDefSQLBinds(users.C("name").In(ip))
+	assert.Equal(t, "users.name IN (?)", sql)
+	assert.Equal(t, []interface{}{ip}, binds)
+	names := pq.StringArray{"root", "admin"}
+	sql, binds = asDefSQLBinds(users.C("name").In(names))
+	assert.Equal(t, "users.name IN (?)", sql)
+	assert.Equal(t, []interface{}{names}, binds)
+}
+
+func TestBetween(t *testing.T) {
+	orders := Table("orders", Column("id", Int()), Column("total", Int()), Column("day", Varchar()))
+
+	sql, binds := asDefSQLBinds(orders.C("total").Between(10, 100))
+	assert.Equal(t, "orders.total BETWEEN ? AND ?", sql)
+	assert.Equal(t, []interface{}{10, 100}, binds)
+
+	sql, binds = asDefSQLBinds(NotBetween(orders.C("day"), "2016-01-01", "2016-12-31"))
+	assert.Equal(t, "orders.day NOT BETWEEN ? AND ?", sql)
+	assert.Equal(t, []interface{}{"2016-01-01", "2016-12-31"}, binds)
+
+	statement := Select(orders.C("id")).
+		From(orders).
+		Where(orders.C("id").Gt(5), orders.C("total").Between(10, 100), orders.C("day").NotEq("2016-01-01")).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM orders\nWHERE (id > $1 AND total BETWEEN $2 AND $3 AND day != $4);", statement.SQL())
+	assert.Equal(t, []interface{}{5, 10, 100, "2016-01-01"}, statement.Bindings())
+}
+
+func TestIsDistinctFrom(t *testing.T) {
+	orders := Table("orders", Column("id", Int()), Column("status", Varchar()))
+
+	sql, binds := asSQLBinds(orders.C("status").IsDistinctFrom("done"), NewDialect("postgres"))
+	assert.Equal(t, "orders.status IS DISTINCT FROM $1", sql)
+	assert.Equal(t, []interface{}{"done"}, binds)
+
+	sql, binds = asDefSQLBinds(orders.C("status").IsNotDistinctFrom(nil))
+	assert.Equal(t, "orders.status IS NOT DISTINCT FROM ?", sql)
+	assert.Equal(t, []interface{}{nil}, binds)
+
+	mysql := NewDialect("mysql")
+
+	sql, binds = asSQLBinds(orders.C("status").IsNotDistinctFrom("done"), mysql)
+	assert.Equal(t, "orders.status <=> ?", sql)
+	assert.Equal(t, []interface{}{"done"}, binds)
+
+	statement := Select(orders.C("id")).
+		From(orders).
+		Where(IsDistinctFrom(orders.C("status"), "done")).
+		Build(mysql)
+	assert.Equal(t, "SELECT id\nFROM orders\nWHERE NOT (status <=> ?);", statement.SQL())
+	assert.Equal(t, []interface{}{"done"}, statement.Bindings())
+}
+
+func TestCountExists(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+	sessions := Table("sessions", Column("id", Int()), Column("user_id", Int()), Column("active", Boolean()))
+
+	sessionCount := Subquery(Select(CountStar()).
+		From(sessions).
+		Where(sessions.C("user_id").Eq(users.C("id")), sessions.C("active").Eq(true)))
+	sel := Select(users.C("email")).From(users).Where(Gt(sessionCount, 0))
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.CountExists = true
+	statement, err := BuildStmt(context, sel)
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT email\n"+
+		"FROM users\n"+
+		"WHERE EXISTS(SELECT 1\n"+
+		"FROM sessions\n"+
+		"WHERE (sessions.user_id = users.id AND sessions.active = $1));", statement.SQL())
+	assert.Equal(t, []interface{}{true}, statement.Bindings())
+
+	context = NewCompilerContext(NewDialect("postgres"))
+	context.CountExists = true
+	statement, err = BuildStmt(context, sel.Where(Eq(sessionCount, 0)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT email\n"+
+		"FROM users\n"+
+		"WHERE NOT EXISTS(SELECT 1\n"+
+		"FROM sessions\n"+
+		"WHERE (sessions.user_id = users.id AND sessions.active = $1));", statement.SQL())
+	assert.Equal(t, []interface{}{true}, statement.Bindings())
+
+	// opt-in
+	statement = sel.Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT email\n"+
+		"FROM users\n"+
+		"WHERE (SELECT COUNT(*)\n"+
+		"FROM sessions\n"+
+		"WHERE (sessions.user_id = users.id AND sessions.active = $1)) > $2;", statement.SQL())
+	assert.Equal(t, []interface{}{true, 0}, statement.Bindings())
+
+	// the comparisons that are not equivalent are left as is
+	context = NewCompilerContext(NewDialect("postgres"))
+	context.CountExists = true
+	statement, err = BuildStmt(context, sel.Where(Gt(sessionCount, 1)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT email\n"+
+		"FROM users\n"+
+		"WHERE (SELECT COUNT(*)\n"+
+		"FROM sessions\n"+
+		"WHERE (sessions.user_id = users.id AND sessions.active = $1)) > $2;", statement.SQL())
+	assert.Equal(t, []interface{}{true, 1}, statement.Bindings())
+}
+
+func TestScalarSubqueryLatest(t *testing.T) {
+	items := Table("items", Column("id", Int()), Column("name", Varchar()), Column("active", Boolean()))
+	prices := Table("prices", Column("item", Int()), Column("price", Int()), Column("currency", Varchar()), Column("ts", Timestamp()))
+
+	latest := Subquery(Select(prices.C("price")).
+		From(prices).
+		Where(prices.C("item").Eq(items.C("id")), prices.C("currency").Eq("EUR")).
+		OrderBy(prices.C("ts")).Desc().
+		LimitCount(1))
+
+	statement := Select(items.C("name"), As(latest, "price")).
+		From(items).
+		Where(items.C("active").Eq(true)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT name, (SELECT prices.price\n"+
+		"FROM prices\n"+
+		"WHERE (prices.item = items.id AND prices.currency = $1)\n"+
+		"ORDER BY prices.ts DESC\n"+
+		"LIMIT 1) AS price\n"+
+		"FROM items\n"+
+		"WHERE active = $2;", statement.SQL())
+	assert.Equal(t, []interface{}{"EUR", true}, statement.Bindings())
+}
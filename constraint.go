@@ -2,38 +2,68 @@ package qb
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
 // Null generates generic null constraint
 func Null() ConstraintElem {
-	return ConstraintElem{"NULL"}
+	return ConstraintElem{Name: "NULL"}
 }
 
 // NotNull generates generic not null constraint
 func NotNull() ConstraintElem {
-	return ConstraintElem{"NOT NULL"}
+	return ConstraintElem{Name: "NOT NULL"}
 }
 
 // Default generates generic default constraint
+// The value is rendered when the column is, with the dialect quoting for the
+// strings. Numbers and booleans are left unquoted, and nil gives DEFAULT NULL
 func Default(value interface{}) ConstraintElem {
-	return ConstraintElem{fmt.Sprintf("DEFAULT '%v'", value)}
+	return ConstraintElem{
+		Name:         "DEFAULT " + defaultLiteral(&DefaultDialect{}, value),
+		defaultValue: value,
+		isDefault:    true,
+	}
+}
+
+// defaultLiteral renders a default value as an sql literal of the dialect
+func defaultLiteral(dialect Dialect, value interface{}) string {
+	if value == nil {
+		return "NULL"
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Bool:
+		return strings.ToUpper(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(value)
+	}
+	if b, ok := value.([]byte); ok {
+		return dialect.QuoteLiteral(string(b))
+	}
+	return dialect.QuoteLiteral(fmt.Sprint(value))
 }
 
 // Unique generates generic unique constraint
 // if cols are given, then composite unique constraint will be built
 func Unique() ConstraintElem {
-	return ConstraintElem{"UNIQUE"}
+	return ConstraintElem{Name: "UNIQUE"}
 }
 
 // Constraint generates a custom constraint due to variation of dialects
 func Constraint(name string) ConstraintElem {
-	return ConstraintElem{name}
+	return ConstraintElem{Name: name}
 }
 
 // ConstraintElem is the definition of column & table constraints
 type ConstraintElem struct {
 	Name string
+
+	defaultValue interface{}
+	isDefault    bool
 }
 
 // String returns the constraint as an sql clause
@@ -41,6 +71,14 @@ func (c ConstraintElem) String() string {
 	return c.Name
 }
 
+// sql returns the constraint as an sql clause of the dialect
+func (c ConstraintElem) sql(dialect Dialect) string {
+	if c.isDefault {
+		return "DEFAULT " + defaultLiteral(dialect, c.defaultValue)
+	}
+	return c.Name
+}
+
 // PrimaryKey generates a primary key constraint of any table
 func PrimaryKey(cols ...string) PrimaryKeyConstraint {
 	return PrimaryKeyConstraint{cols}
@@ -89,9 +127,16 @@ type ForeignKeyConstraint struct {
 	ActionOnDelete string
 }
 
+// String returns the foreign key as a CREATE TABLE sql clause
 func (fkey ForeignKeyConstraint) String(dialect Dialect) string {
+	return "\t" + fkey.definition(dialect)
+}
+
+// definition returns the FOREIGN KEY ... REFERENCES ... definition of the
+// foreign key
+func (fkey ForeignKeyConstraint) definition(dialect Dialect) string {
 	ddl := fmt.Sprintf(
-		"\tFOREIGN KEY(%s) REFERENCES %s(%s)",
+		"FOREIGN KEY(%s) REFERENCES %s(%s)",
 		strings.Join(dialect.EscapeAll(fkey.Cols), ", "),
 		dialect.Escape(fkey.RefTable),
 		strings.Join(dialect.EscapeAll(fkey.RefCols), ", "),
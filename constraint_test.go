@@ -9,9 +9,13 @@ func TestConstraints(t *testing.T) {
 
 	assert.Equal(t, Constraint("NULL"), Null())
 	assert.Equal(t, Constraint("NOT NULL"), NotNull())
-	assert.Equal(t, Constraint("DEFAULT '5'"), Default(5))
+	assert.Equal(t, "DEFAULT 5", Default(5).String())
+	assert.Equal(t, "DEFAULT 2.5", Default(2.5).String())
+	assert.Equal(t, "DEFAULT TRUE", Default(true).String())
+	assert.Equal(t, "DEFAULT NULL", Default(nil).String())
+	assert.Equal(t, "DEFAULT '5'", Default("5").String())
 	assert.Equal(t, Constraint("UNIQUE"), Unique())
-	assert.Equal(t, ConstraintElem{"CHECK id > 5"}, Constraint("CHECK id > 5"))
+	assert.Equal(t, ConstraintElem{Name: "CHECK id > 5"}, Constraint("CHECK id > 5"))
 	assert.Equal(t, "NOT NULL", NotNull().String())
 
 	sqlite := NewDialect("sqlite3")
@@ -0,0 +1,66 @@
+package qb
+
+// CreateTableAs generates a CREATE TABLE ... AS SELECT statement, that
+// creates the table 'name' from the result of 'sel'
+func CreateTableAs(name string, sel SelectStmt) CreateTableAsStmt {
+	return CreateTableAsStmt{
+		name: name,
+		sel:  sel,
+	}
+}
+
+// CreateTableAsStmt is the base struct for building CREATE TABLE AS statements
+type CreateTableAsStmt struct {
+	name        string
+	sel         SelectStmt
+	ifNotExists bool
+	noData      bool
+}
+
+// IfNotExists makes the statement a CREATE TABLE IF NOT EXISTS
+func (s CreateTableAsStmt) IfNotExists() CreateTableAsStmt {
+	s.ifNotExists = true
+	return s
+}
+
+// WithNoData makes the statement create the table without filling it
+// NOTE: WITH NO DATA is postgres specific
+func (s CreateTableAsStmt) WithNoData() CreateTableAsStmt {
+	s.noData = true
+	return s
+}
+
+// Accept calls the compiler VisitCreateTableAs function
+func (s CreateTableAsStmt) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitCreateTableAs(context, s)
+}
+
+// Build generates a statement out of CreateTableAsStmt object
+// It panics if the statement cannot be compiled
+func (s CreateTableAsStmt) Build(dialect Dialect) *Stmt {
+	return mustBuildStmt(dialect, s)
+}
+
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s CreateTableAsStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
+
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s CreateTableAsStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s CreateTableAsStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
+
+// String compiles the statement with the default dialect, for inspection
+func (s CreateTableAsStmt) String() string {
+	return stmtString(s)
+}
@@ -0,0 +1,33 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateTableAs(t *testing.T) {
+	postgres := NewDialect("postgres")
+	postgres.SetEscaping(true)
+
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("active", Boolean()),
+	)
+
+	ctas := CreateTableAs("active_users", Select(users.C("id")).From(users).Where(users.C("active").Eq(true)))
+
+	statement := ctas.Build(NewDialect("sqlite3"))
+	assert.Equal(t, "CREATE TABLE active_users AS\nSELECT id\nFROM users\nWHERE active = ?;", statement.SQL())
+	assert.Equal(t, []interface{}{true}, statement.Bindings())
+
+	statement = ctas.IfNotExists().Build(NewDialect("mysql"))
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS active_users AS\nSELECT id\nFROM users\nWHERE active = ?;", statement.SQL())
+
+	statement = ctas.IfNotExists().WithNoData().Build(postgres)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS \"active_users\" AS\nSELECT \"id\"\nFROM \"users\"\nWHERE \"active\" = $1\nWITH NO DATA;", statement.SQL())
+	assert.Equal(t, []interface{}{true}, statement.Bindings())
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("sqlite3")), ctas.WithNoData())
+	assert.EqualError(t, err, "WITH NO DATA is not supported by this compiler")
+}
@@ -0,0 +1,148 @@
+package qb
+
+import "fmt"
+
+// With returns a new CTEClause, a common table expression named 'name'
+// The returned clause is a Selectable that can be used in a FROM clause
+// once the CTE is attached to the statement with its With() method
+func With(name string, sel SelectStmt) CTEClause {
+	return CTEClause{
+		Name:   name,
+		Select: sel,
+	}
+}
+
+// WithStatement returns a new data-modifying CTEClause named 'name', made of
+// a DELETE, UPDATE or INSERT statement. Its columns are the ones of the
+// statement RETURNING clause
+// NOTE: data-modifying CTEs are postgres specific
+func WithStatement(name string, statement Clause) CTEClause {
+	return CTEClause{
+		Name:      name,
+		Statement: statement,
+	}
+}
+
+// WithRecursive returns a new recursive CTEClause named 'name', with the
+// given column names. Its statement, that can refer to the CTE itself, is
+// set with As(), and is usually a UnionAll() of a base select and of a
+// select from the CTE:
+// nums := WithRecursive("nums", "n")
+// nums = nums.As(UnionAll(Select(SQLText("1")), Select(...).From(nums)))
+func WithRecursive(name string, columns ...string) CTEClause {
+	return CTEClause{
+		Name:      name,
+		Columns:   columns,
+		Recursive: true,
+	}
+}
+
+// CTEClause is a common table expression
+// When used in a FROM clause, it compiles to the CTE name
+type CTEClause struct {
+	Name      string
+	Select    SelectStmt
+	Statement Clause
+	Columns   []string
+	Recursive bool
+}
+
+// As returns a copy of the CTE with the given statement, typically for a
+// recursive CTE whose statement refers to the CTE itself
+func (c CTEClause) As(statement Clause) CTEClause {
+	c.Statement = statement
+	return c
+}
+
+// body returns the statement of the CTE
+func (c CTEClause) body() Clause {
+	if c.Statement != nil {
+		return c.Statement
+	}
+	return c.Select
+}
+
+// selected returns the clauses selected or returned by the CTE statement
+func (c CTEClause) selected() []Clause {
+	switch statement := c.Statement.(type) {
+	case nil:
+		return c.Select.sel
+	case DeleteStmt:
+		return statement.returning
+	case UpdateStmt:
+		return statement.returning
+	case InsertStmt:
+		return statement.returning
+	case CompoundStmt:
+		if len(statement.selects) > 0 {
+			return statement.selects[0].sel
+		}
+	}
+	return nil
+}
+
+// Accept calls the compiler VisitCTE function
+func (c CTEClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitCTE(context, c)
+}
+
+// All returns the columns of the CTE
+func (c CTEClause) All() []Clause {
+	var clauses []Clause
+	for _, col := range c.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the columns of the CTE, which are the declared Columns
+// or else the named columns selected or returned by the CTE statement, with
+// their "Table" field set to the CTE name
+func (c CTEClause) ColumnList() []ColumnElem {
+	var cols []ColumnElem
+	if len(c.Columns) > 0 {
+		for _, name := range c.Columns {
+			cols = append(cols, ColumnElem{Name: name, Table: c.Name})
+		}
+		return cols
+	}
+	selected := c.selected()
+	for i, name := range columnNames(selected) {
+		if name == "" {
+			continue
+		}
+		col := ColumnElem{Name: name}
+		if selCol, ok := selected[i].(ColumnElem); ok {
+			col = selCol
+		}
+		col.Table = c.Name
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// C returns the CTE column with the given name
+func (c CTEClause) C(name string) ColumnElem {
+	for _, col := range c.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	panic(fmt.Sprintf("No such column '%s' in CTE %s", name, c.Name))
+}
+
+// DefaultName returns the CTE name
+func (c CTEClause) DefaultName() string {
+	return c.Name
+}
+
+// WithClause is a 'WITH <name> AS (<select>), ...' sql clause that
+// prefixes a statement
+type WithClause struct {
+	CTEs []CTEClause
+}
+
+// Accept calls the compiler VisitWith function
+func (c WithClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitWith(context, c)
+}
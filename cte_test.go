@@ -0,0 +1,350 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCTE(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", BigInt()),
+		Column("email", Varchar()),
+		Column("active", Boolean()),
+		PrimaryKey("id"),
+	)
+	sessions := Table(
+		"sessions",
+		Column("user_id", BigInt()),
+		Column("auth_token", Varchar()),
+	)
+
+	activeUsers := With(
+		"active_users",
+		Select(users.C("id"), users.C("email")).
+			From(users).
+			Where(Eq(users.C("active"), true)),
+	)
+
+	assert.Equal(t, []ColumnElem{
+		activeUsers.C("id"),
+		activeUsers.C("email"),
+	}, activeUsers.ColumnList())
+	assert.Equal(t, "active_users", activeUsers.C("id").Table)
+	assert.Equal(t, 2, len(activeUsers.All()))
+	assert.Panics(t, func() { activeUsers.C("active") })
+
+	sel := Select(activeUsers.C("email")).
+		From(activeUsers).
+		Where(Eq(activeUsers.C("id"), 5)).
+		With(activeUsers)
+
+	sql, binds := asSQLBinds(sel, NewDialect("postgres"))
+	assert.Equal(t, `WITH active_users AS (SELECT id, email
+FROM users
+WHERE active = $1)
+SELECT email
+FROM active_users
+WHERE id = $2`, sql)
+	assert.Equal(t, []interface{}{true, 5}, binds)
+
+	sel = Select(activeUsers.C("email"), sessions.C("auth_token")).
+		From(activeUsers).
+		InnerJoin(sessions, activeUsers.C("id"), sessions.C("user_id")).
+		Where(Eq(activeUsers.C("id"), 5)).
+		With(activeUsers)
+
+	sql, binds = asDefSQLBinds(sel)
+	assert.Equal(t, `WITH active_users AS (SELECT id, email
+FROM users
+WHERE active = ?)
+SELECT active_users.email, sessions.auth_token
+FROM active_users
+INNER JOIN sessions ON active_users.id = sessions.user_id
+WHERE active_users.id = ?`, sql)
+	assert.Equal(t, []interface{}{true, 5}, binds)
+}
+
+func TestCTEChained(t *testing.T) {
+	orders := Table(
+		"orders",
+		Column("id", BigInt()),
+		Column("region", Varchar()),
+		Column("amount", Int()),
+		Column("status", Varchar()),
+	)
+
+	regionalSales := With(
+		"regional_sales",
+		Select(orders.C("region"), As(Sum(orders.C("amount")), "total_sales")).
+			From(orders).
+			Where(Eq(orders.C("status"), "paid")).
+			GroupBy(orders.C("region")),
+	)
+	topRegions := With(
+		"top_regions",
+		Select(regionalSales.C("region")).
+			From(regionalSales).
+			Where(Gt(regionalSales.C("total_sales"), 1000)),
+	)
+
+	expected := `WITH regional_sales AS (SELECT region, SUM(amount) AS total_sales
+FROM orders
+WHERE status = $1
+GROUP BY region), top_regions AS (SELECT region
+FROM regional_sales
+WHERE total_sales > $2)
+SELECT region
+FROM top_regions
+WHERE region != $3`
+
+	sel := Select(topRegions.C("region")).
+		From(topRegions).
+		Where(NotEq(topRegions.C("region"), "north")).
+		With(regionalSales, topRegions)
+
+	sql, binds := asSQLBinds(sel, NewDialect("postgres"))
+	assert.Equal(t, expected, sql)
+	assert.Equal(t, []interface{}{"paid", 1000, "north"}, binds)
+
+	assert.Equal(t, expected, asSQL(sel.With(), NewDialect("postgres")))
+
+	sel = Select(topRegions.C("region")).
+		From(topRegions).
+		Where(NotEq(topRegions.C("region"), "north")).
+		With(regionalSales).
+		With(topRegions)
+
+	sql, binds = asSQLBinds(sel, NewDialect("postgres"))
+	assert.Equal(t, expected, sql)
+	assert.Equal(t, []interface{}{"paid", 1000, "north"}, binds)
+}
+
+func TestCTEStrictScope(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", BigInt()),
+		Column("active", Boolean()),
+	)
+	activeUsers := With("active_users", Select(users.C("id")).From(users).Where(Eq(users.C("active"), true)))
+	activeIds := With("active_ids", Select(activeUsers.C("id")).From(activeUsers))
+
+	strictBuild := func(sel SelectStmt) (*Stmt, error) {
+		context := NewCompilerContext(NewDialect("postgres"))
+		context.Strict = true
+		return BuildStmt(context, sel)
+	}
+
+	sel := Select(activeIds.C("id")).From(activeIds).With(activeUsers, activeIds)
+	first, err := strictBuild(sel)
+	assert.Nil(t, err)
+	for i := 0; i < 10; i++ {
+		statement, err := strictBuild(sel)
+		assert.Nil(t, err)
+		assert.Equal(t, first.SQL(), statement.SQL())
+	}
+
+	// a CTE in a subquery sees the enclosing ones
+	sel = Select(users.C("id")).
+		From(users).
+		Where(In(users.C("id"), Select(activeIds.C("id")).From(activeIds).With(activeIds))).
+		With(activeUsers)
+	_, err = strictBuild(sel)
+	assert.Nil(t, err)
+
+	// the subquery CTEs are not visible outside of it
+	sel = Select(users.C("id")).
+		From(users).
+		Where(
+			Exists(Select(activeIds.C("id")).From(activeIds).With(activeUsers, activeIds)),
+			In(users.C("id"), Select(activeIds.C("id")).From(activeIds)),
+		)
+	_, err = strictBuild(sel)
+	assert.EqualError(t, err, "CTE active_ids is not declared in a WITH clause")
+
+	_, err = strictBuild(Select(activeIds.C("id")).From(activeIds).With(activeIds))
+	assert.EqualError(t, err, "CTE active_users is not declared in a WITH clause")
+
+	// not strict: left to the database
+	_, err = BuildStmt(NewCompilerContext(NewDialect("postgres")), Select(activeIds.C("id")).From(activeIds))
+	assert.Nil(t, err)
+}
+
+func TestWritableCTE(t *testing.T) {
+	src := Table("src", Column("id", BigInt()), Column("name", Varchar()), Column("expired", Boolean()))
+	dst := Table("dst", Column("id", BigInt()), Column("name", Varchar()))
+	postgres := NewDialect("postgres")
+
+	moved := WithStatement("moved", Delete(src).Where(src.C("expired").Eq(true)).Returning(src.C("id"), src.C("name")))
+	assert.Equal(t, []ColumnElem{moved.C("id"), moved.C("name")}, moved.ColumnList())
+
+	insert := Insert(dst).
+		Select(Select(moved.C("id"), moved.C("name")).From(moved), "id", "name").
+		With(moved)
+
+	sql, binds := asSQLBinds(insert, postgres)
+	assert.Equal(t, `WITH moved AS (DELETE FROM src
+WHERE src.expired = $1
+RETURNING id, name)
+INSERT INTO dst(id, name)
+SELECT id, name
+FROM moved`, sql)
+	assert.Equal(t, []interface{}{true}, binds)
+
+	all := WithStatement("moved", Delete(src).Unsafe().Returning(SQLText("*")))
+	sql, _ = asSQLBinds(Insert(dst).Select(Select(SQLText("*")).From(all)).With(all), postgres)
+	assert.Equal(t, "WITH moved AS (DELETE FROM src\nRETURNING *)\nINSERT INTO dst\nSELECT *\nFROM moved", sql)
+
+	renamed := WithStatement("renamed", Update(src).Values(map[string]interface{}{"name": "x"}).Where(src.C("id").Eq(1)).Returning(src.C("id")))
+	sql, binds = asSQLBinds(Update(dst).Values(map[string]interface{}{"name": "x"}).
+		From(renamed).Where(dst.C("id").Eq(renamed.C("id"))).With(renamed), postgres)
+	assert.Equal(t, `WITH renamed AS (UPDATE src
+SET name = $1
+WHERE id = $2
+RETURNING id)
+UPDATE dst
+SET name = $3
+FROM renamed
+WHERE dst.id = renamed.id`, sql)
+	assert.Equal(t, []interface{}{"x", 1, "x"}, binds)
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("sqlite3")), insert)
+	assert.EqualError(t, err, "Data-modifying CTE moved is not supported by this compiler")
+
+	_, err = BuildStmt(NewCompilerContext(postgres), Insert(dst).Values(map[string]interface{}{"id": 1}).Select(Select(moved.C("id")).From(moved)))
+	assert.EqualError(t, err, "Insert cannot have both values and a select")
+}
+
+func TestCTERecursive(t *testing.T) {
+	categories := Table(
+		"categories",
+		Column("id", BigInt()),
+		Column("parent_id", BigInt()),
+		Column("name", Varchar()),
+	)
+
+	tree := WithRecursive("tree", "id", "name")
+	tree = tree.As(UnionAll(
+		Select(categories.C("id"), categories.C("name")).
+			From(categories).
+			Where(Eq(categories.C("id"), 1)),
+		Select(categories.C("id"), categories.C("name")).
+			From(categories).
+			InnerJoin(tree, categories.C("parent_id"), tree.C("id")).
+			Where(NotEq(categories.C("name"), "archive")),
+	))
+	assert.Equal(t, []ColumnElem{
+		{Name: "id", Table: "tree"},
+		{Name: "name", Table: "tree"},
+	}, tree.ColumnList())
+
+	sel := Select(tree.C("name")).
+		From(tree).
+		Where(NotEq(tree.C("id"), 1)).
+		With(tree)
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.Strict = true
+	statement, err := BuildStmt(context, sel)
+	assert.Nil(t, err)
+	assert.Equal(t, `WITH RECURSIVE tree(id, name) AS ((SELECT id, name
+FROM categories
+WHERE id = $1)
+UNION ALL
+(SELECT categories.id, categories.name
+FROM categories
+INNER JOIN tree ON categories.parent_id = tree.id
+WHERE categories.name != $2))
+SELECT name
+FROM tree
+WHERE id != $3;`, statement.SQL())
+	assert.Equal(t, []interface{}{1, "archive", 1}, statement.Bindings())
+}
+
+func TestCTEOtherStatements(t *testing.T) {
+	orders := Table(
+		"orders",
+		Column("id", BigInt()),
+		Column("user_id", BigInt()),
+		Column("amount", Int()),
+	)
+	users := Table(
+		"users",
+		Column("id", BigInt()),
+		Column("vip", Boolean()),
+	)
+
+	bigSpenders := With("big_spenders",
+		Select(orders.C("user_id")).From(orders).Where(orders.C("amount").Gt(1000)))
+
+	upd := Update(users).
+		Values(map[string]interface{}{"vip": true}).
+		From(bigSpenders).
+		Where(users.C("id").Eq(bigSpenders.C("user_id"))).
+		With(bigSpenders)
+	sql, binds := asSQLBinds(upd, NewDialect("postgres"))
+	assert.Equal(t, `WITH big_spenders AS (SELECT user_id
+FROM orders
+WHERE amount > $1)
+UPDATE users
+SET vip = $2
+FROM big_spenders
+WHERE users.id = big_spenders.user_id`, sql)
+	assert.Equal(t, []interface{}{1000, true}, binds)
+
+	union := Union(
+		Select(bigSpenders.C("user_id")).From(bigSpenders),
+		Select(users.C("id")).From(users).Where(users.C("vip").Eq(true)),
+	).With(bigSpenders)
+	sql, binds = asSQLBinds(union, NewDialect("sqlite3"))
+	assert.Equal(t, `WITH big_spenders AS (SELECT user_id
+FROM orders
+WHERE amount > ?)
+SELECT user_id
+FROM big_spenders
+UNION
+SELECT id
+FROM users
+WHERE vip = ?`, sql)
+	assert.Equal(t, []interface{}{1000, true}, binds)
+}
+
+func TestCTEChunkedDelete(t *testing.T) {
+	events := Table(
+		"events",
+		Column("id", BigInt()),
+		Column("kind", Varchar()),
+		Column("created_at", Timestamp()),
+	)
+
+	doomed := With("doomed",
+		Select(events.C("id")).
+			From(events).
+			Where(events.C("kind").Eq("debug"), events.C("created_at").Lt("2016-01-01")).
+			OrderBy(events.C("id")).
+			LimitCount(1000))
+
+	del := Delete(events).
+		With(doomed).
+		Where(events.C("id").In(Select(doomed.C("id")).From(doomed)), events.C("kind").Eq("debug"))
+
+	for _, dialect := range []Dialect{NewDialect("postgres"), NewDialect("sqlite3")} {
+		context := NewCompilerContext(dialect)
+		context.CheckBinds = true
+		_, err := BuildStmt(context, del)
+		assert.Nil(t, err)
+	}
+
+	statement := del.Build(NewDialect("postgres"))
+	assert.Equal(t, `WITH doomed AS (SELECT id
+FROM events
+WHERE (kind = $1 AND created_at < $2)
+ORDER BY id ASC
+LIMIT 1000)
+DELETE FROM events
+WHERE (events.id IN (SELECT doomed.id
+FROM doomed) AND events.kind = $3);`, statement.SQL())
+	// the CTE binds come first
+	assert.Equal(t, []interface{}{"debug", "2016-01-01", "debug"}, statement.Bindings())
+}
@@ -5,7 +5,7 @@ package qb
 func Delete(table TableElem) DeleteStmt {
 	return DeleteStmt{
 		table:     table,
-		returning: []ColumnElem{},
+		returning: []Clause{},
 	}
 }
 
@@ -13,19 +13,54 @@ func Delete(table TableElem) DeleteStmt {
 type DeleteStmt struct {
 	table     TableElem
 	where     *WhereClause
-	returning []ColumnElem
+	returning []Clause
+	with      WithClause
+	unsafe    bool
+	orderBy   *OrderByClause
+	count     *int
+}
+
+// Unsafe marks the statement as deleting all the rows on purpose when it has
+// no where clause, so it is accepted in safe mode
+func (s DeleteStmt) Unsafe() DeleteStmt {
+	s.unsafe = true
+	return s
 }
 
 // Where adds a where clause to the current delete statement
-func (s DeleteStmt) Where(clause Clause) DeleteStmt {
-	s.where = &WhereClause{clause}
+// Several clauses are combined with AND
+func (s DeleteStmt) Where(clauses ...Clause) DeleteStmt {
+	where := Where(clauses...)
+	s.where = &where
+	return s
+}
+
+// OrderBy sets the order in which the rows are deleted, to be used with
+// LimitCount
+// NOTE: only mysql supports it
+func (s DeleteStmt) OrderBy(columns ...Clause) DeleteStmt {
+	s.orderBy = &OrderByClause{columns, "ASC"}
 	return s
 }
 
-// Returning accepts the column names as strings and forms the returning array of insert statement
+// LimitCount bounds the number of deleted rows, for chunked deletes
+// NOTE: only mysql supports it
+func (s DeleteStmt) LimitCount(count int) DeleteStmt {
+	s.count = &count
+	return s
+}
+
+// Returning accepts the columns or expressions, possibly aliased with As(),
+// and forms the returning array of the statement
 // NOTE: Please use it in only postgres dialect, otherwise it'll crash
-func (s DeleteStmt) Returning(cols ...ColumnElem) DeleteStmt {
-	s.returning = append(s.returning, cols...)
+func (s DeleteStmt) Returning(cols ...Clause) DeleteStmt {
+	s.returning = append(append([]Clause{}, s.returning...), cols...)
+	return s
+}
+
+// With appends common table expressions to the statement
+func (s DeleteStmt) With(ctes ...CTEClause) DeleteStmt {
+	s.with.CTEs = append(append([]CTEClause{}, s.with.CTEs...), ctes...)
 	return s
 }
 
@@ -35,11 +70,31 @@ func (s DeleteStmt) Accept(context *CompilerContext) string {
 }
 
 // Build generates a statement out of DeleteStmt object
+// It panics if the statement cannot be compiled
 func (s DeleteStmt) Build(dialect Dialect) *Stmt {
-	context := NewCompilerContext(dialect)
-	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
-	statement.AddBinding(context.Binds...)
+	return mustBuildStmt(dialect, s)
+}
+
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s DeleteStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
+
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s DeleteStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s DeleteStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
 
-	return statement
+// String compiles the statement with the default dialect, for inspection
+func (s DeleteStmt) String() string {
+	return stmtString(s)
 }
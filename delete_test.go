@@ -48,3 +48,77 @@ func TestDelete(t *testing.T) {
 	statement = Delete(users).Build(sqlite)
 	assert.Equal(t, "DELETE FROM users;", statement.SQL())
 }
+
+func TestDeleteWhereClauses(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Varchar().Size(36)),
+		Column("email", Varchar()).Unique(),
+	)
+
+	statement := Delete(users).Where(users.C("id").Eq(5)).Build(NewDialect("sqlite3"))
+	assert.Equal(t, "DELETE FROM users\nWHERE users.id = ?;", statement.SQL())
+
+	statement = Delete(users).Where(users.C("id").Eq(5), users.C("email").Eq("al@pacino.com")).Build(NewDialect("sqlite3"))
+	assert.Equal(t, "DELETE FROM users\nWHERE (users.id = ? AND users.email = ?);", statement.SQL())
+	assert.Equal(t, []interface{}{5, "al@pacino.com"}, statement.Bindings())
+}
+
+func TestDeleteReturningExpressions(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	postgres := NewDialect("postgres")
+
+	del := Delete(users).Where(Eq(users.C("id"), 5))
+
+	statement := del.Returning(users.C("id"), As(SQLText("now()"), "deleted_at")).Build(postgres)
+	assert.Equal(t, "DELETE FROM users\nWHERE users.id = $1\nRETURNING id, now() AS deleted_at;", statement.SQL())
+
+	statement = del.Returning(SQLText("*")).Build(postgres)
+	assert.Equal(t, "DELETE FROM users\nWHERE users.id = $1\nRETURNING *;", statement.SQL())
+
+	// Returning returns a copy
+	first := del.Returning(users.C("id"))
+	second := first.Returning(users.C("email"))
+	first.Returning(SQLText("*"))
+	assert.Equal(t, "DELETE FROM users\nWHERE users.id = $1\nRETURNING id, email;", second.Build(postgres).SQL())
+}
+
+func TestDeleteOrderByLimit(t *testing.T) {
+	sessions := Table("sessions", Column("id", Int()), Column("expires_at", Timestamp()))
+
+	del := Delete(sessions).
+		Where(sessions.C("expires_at").Lt("2016-01-01")).
+		OrderBy(sessions.C("expires_at")).
+		LimitCount(1000)
+
+	sql, binds, err := del.ToSQL(NewDialect("mysql"))
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE FROM sessions\nWHERE sessions.expires_at < ?\nORDER BY expires_at ASC\nLIMIT 1000;", sql)
+	assert.Equal(t, []interface{}{"2016-01-01"}, binds)
+
+	_, _, err = del.ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "DELETE ... ORDER BY and LIMIT are not supported by this compiler")
+
+	_, _, err = Delete(sessions).Unsafe().LimitCount(-1).ToSQL(NewDialect("mysql"))
+	assert.EqualError(t, err, "Invalid negative limit: -1")
+
+	sql, _, err = Update(sessions).
+		Values(map[string]interface{}{"expires_at": nil}).
+		Unsafe().
+		OrderBy(Desc(sessions.C("id"))).
+		LimitCount(10).
+		ToSQL(NewDialect("mysql"))
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE sessions\nSET expires_at = ?\nORDER BY id DESC\nLIMIT 10;", sql)
+
+	_, _, err = Update(sessions).
+		Values(map[string]interface{}{"expires_at": nil}).
+		Unsafe().
+		LimitCount(10).
+		ToSQL(NewDialect("sqlite3"))
+	assert.EqualError(t, err, "UPDATE ... ORDER BY and LIMIT are not supported by this compiler")
+}
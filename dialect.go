@@ -1,5 +1,7 @@
 package qb
 
+import "strings"
+
 // NewDialect returns a dialect pointer given driver
 func NewDialect(driver string) Dialect {
 	factory, ok := DialectRegistry[driver]
@@ -27,13 +29,26 @@ type Dialect interface {
 	CompileType(t TypeElem) string
 	Escape(str string) string
 	EscapeAll([]string) []string
+	Quote(str string) string
+	IsReserved(str string) bool
+	QuoteLiteral(str string) string
 	SetEscaping(escaping bool)
 	Escaping() bool
 	AutoIncrement(column *ColumnElem) string
 	SupportsUnsigned() bool
+	SupportsTableStorage() bool
+	SupportsInlineComments() bool
+	SupportsCommentOn() bool
+	SupportsAlterForeignKey() bool
+	SetTransaction(level IsolationLevel, session bool) (string, error)
 	Driver() string
 }
 
+// quoteString single-quotes a string, doubling the embedded quotes
+func quoteString(str string) string {
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
+
 // common escape all
 func escapeAll(dialect Dialect, strings []string) []string {
 	for k, v := range strings {
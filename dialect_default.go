@@ -25,6 +25,23 @@ func (d *DefaultDialect) EscapeAll(strings []string) []string {
 	return escapeAll(d, strings[0:])
 }
 
+// Quote wraps the string with the standard sql identifier quotes, whether
+// the escaping is on or not
+func (d *DefaultDialect) Quote(str string) string {
+	return fmt.Sprintf("\"%s\"", str)
+}
+
+// IsReserved returns whether the string is a reserved word of the dialect,
+// which cannot be used as an unquoted identifier
+func (d *DefaultDialect) IsReserved(str string) bool {
+	return isReservedWord(defaultReservedWords, str)
+}
+
+// QuoteLiteral single-quotes a string literal, doubling the embedded quotes
+func (d *DefaultDialect) QuoteLiteral(str string) string {
+	return quoteString(str)
+}
+
 // SetEscaping sets the escaping parameter of dialect
 func (d *DefaultDialect) SetEscaping(escaping bool) {
 	d.escaping = escaping
@@ -48,6 +65,28 @@ func (d *DefaultDialect) AutoIncrement(column *ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *DefaultDialect) SupportsUnsigned() bool { return false }
 
+// SupportsTableStorage returns whether driver supports the storage parameters
+// and tablespace of the tables or not
+func (d *DefaultDialect) SupportsTableStorage() bool { return false }
+
+// SupportsInlineComments returns whether driver supports the comments as
+// COMMENT clauses of the CREATE TABLE or not
+func (d *DefaultDialect) SupportsInlineComments() bool { return false }
+
+// SupportsCommentOn returns whether driver supports the comments as COMMENT ON
+// statements following the CREATE TABLE or not
+func (d *DefaultDialect) SupportsCommentOn() bool { return false }
+
+// SupportsAlterForeignKey returns whether driver supports adding the foreign
+// keys of an existing table or not
+func (d *DefaultDialect) SupportsAlterForeignKey() bool { return true }
+
+// SetTransaction returns the statement that sets the isolation level of the
+// transaction, or of the following transactions of the session
+func (d *DefaultDialect) SetTransaction(level IsolationLevel, session bool) (string, error) {
+	return setTransactionSQL(level, session), nil
+}
+
 // Driver returns the current driver of dialect
 func (d *DefaultDialect) Driver() string {
 	return ""
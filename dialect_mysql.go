@@ -1,6 +1,7 @@
 package qb
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -40,6 +41,25 @@ func (d *MysqlDialect) EscapeAll(strings []string) []string {
 	return escapeAll(d, strings[0:])
 }
 
+// Quote wraps the string with the identifier quotes of the dialect, whether
+// its escaping is on or not
+func (d *MysqlDialect) Quote(str string) string {
+	return fmt.Sprintf("`%s`", str)
+}
+
+// IsReserved returns whether the string is a reserved word of the dialect,
+// which cannot be used as an unquoted identifier
+func (d *MysqlDialect) IsReserved(str string) bool {
+	return isReservedWord(mysqlReservedWords, str)
+}
+
+// QuoteLiteral single-quotes a string literal, doubling the embedded quotes.
+// The backslashes are doubled too, as mysql treats them as escape characters
+// by default
+func (d *MysqlDialect) QuoteLiteral(str string) string {
+	return quoteString(strings.Replace(str, `\`, `\\`, -1))
+}
+
 // SetEscaping sets the escaping parameter of dialect
 func (d *MysqlDialect) SetEscaping(escaping bool) {
 	d.escaping = escaping
@@ -63,6 +83,28 @@ func (d *MysqlDialect) AutoIncrement(column *ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *MysqlDialect) SupportsUnsigned() bool { return true }
 
+// SupportsTableStorage returns whether driver supports the storage parameters
+// and tablespace of the tables or not
+func (d *MysqlDialect) SupportsTableStorage() bool { return false }
+
+// SupportsInlineComments returns whether driver supports the comments as
+// COMMENT clauses of the CREATE TABLE or not
+func (d *MysqlDialect) SupportsInlineComments() bool { return true }
+
+// SupportsCommentOn returns whether driver supports the comments as COMMENT ON
+// statements following the CREATE TABLE or not
+func (d *MysqlDialect) SupportsCommentOn() bool { return false }
+
+// SupportsAlterForeignKey returns whether driver supports adding the foreign
+// keys of an existing table or not
+func (d *MysqlDialect) SupportsAlterForeignKey() bool { return true }
+
+// SetTransaction returns the statement that sets the isolation level of the
+// transaction, or of the following transactions of the session
+func (d *MysqlDialect) SetTransaction(level IsolationLevel, session bool) (string, error) {
+	return setTransactionSQL(level, session), nil
+}
+
 // Driver returns the current driver of dialect
 func (d *MysqlDialect) Driver() string {
 	return "mysql"
@@ -78,6 +120,163 @@ type MysqlCompiler struct {
 	SQLCompiler
 }
 
+// VisitAggregate compiles aggregate functions (COUNT, SUM...).
+// FILTER is not supported by mysql
+func (c MysqlCompiler) VisitAggregate(context *CompilerContext, aggregate AggregateClause) string {
+	if aggregate.filter != nil {
+		context.AddError(errors.New("FILTER is not supported by mysql"))
+	}
+	return c.SQLCompiler.VisitAggregate(context, aggregate)
+}
+
+// VisitBinary compiles a binary expression.
+// IS [NOT] DISTINCT FROM is rendered with the mysql null-safe <=> operator,
+// and the bitwise XOR with ^
+func (c MysqlCompiler) VisitBinary(context *CompilerContext, binary BinaryExpressionClause) string {
+	switch binary.Op {
+	case bitXor:
+		binary.Op = "^"
+		return c.SQLCompiler.VisitBinary(context, binary)
+	case "IS NOT DISTINCT FROM":
+		binary.Op = "<=>"
+		return c.SQLCompiler.VisitBinary(context, binary)
+	case "IS DISTINCT FROM":
+		binary.Op = "<=>"
+		return fmt.Sprintf("NOT (%s)", c.SQLCompiler.VisitBinary(context, binary))
+	}
+	return c.SQLCompiler.VisitBinary(context, binary)
+}
+
+// VisitJoin compiles a JOIN (ON) clause
+// FULL OUTER JOIN is not supported by mysql
+func (c MysqlCompiler) VisitJoin(context *CompilerContext, join JoinClause) string {
+	if join.JoinType == "FULL OUTER JOIN" {
+		context.AddError(errors.New("FULL OUTER JOIN is not supported by mysql"))
+	}
+	return c.SQLCompiler.VisitJoin(context, join)
+}
+
+// VisitIn compiles a IN clause, mysql not supporting a LIMIT in its subquery
+func (c MysqlCompiler) VisitIn(context *CompilerContext, in InClause) string {
+	if list, ok := in.Right.(ListClause); ok && len(list.Clauses) == 1 {
+		if sel, ok := list.Clauses[0].(SelectStmt); ok && sel.count != nil {
+			context.AddError(errors.New("LIMIT in a IN subquery is not supported by mysql"))
+		}
+	}
+	return c.SQLCompiler.VisitIn(context, in)
+}
+
+// VisitMerge reports an error, mysql has no MERGE
+func (MysqlCompiler) VisitMerge(context *CompilerContext, merge MergeStmt) string {
+	context.AddError(errors.New("MERGE is not supported by mysql"))
+	return ""
+}
+
+// VisitInto reports an error, mysql has no SELECT INTO
+func (MysqlCompiler) VisitInto(context *CompilerContext, into IntoClause) string {
+	context.AddError(errors.New("SELECT INTO is not supported by mysql"))
+	return ""
+}
+
+// VisitOrdering compiles an order by expression with its direction.
+// NULLS FIRST and NULLS LAST are not supported by mysql
+func (c MysqlCompiler) VisitOrdering(context *CompilerContext, ordering OrderingClause) string {
+	if ordering.Nulls != "" {
+		context.AddError(fmt.Errorf("NULLS %s is not supported by mysql", ordering.Nulls))
+	}
+	return c.SQLCompiler.VisitOrdering(context, ordering)
+}
+
+// VisitWindow compiles a window function call.
+// EXCLUDE frame options and IGNORE NULLS are not supported by mysql
+func (c MysqlCompiler) VisitWindow(context *CompilerContext, window WindowClause) string {
+	if window.frame != nil && window.frame.Exclude != "" {
+		context.AddError(errors.New("EXCLUDE is not supported by mysql"))
+	}
+	if window.nulls == "IGNORE NULLS" {
+		context.AddError(errors.New("IGNORE NULLS is not supported by mysql"))
+	}
+	return c.SQLCompiler.VisitWindow(context, window)
+}
+
+// VisitExcluded compiles a reference to a column of the row proposed for
+// insertion by an upsert, as VALUES(col)
+func (MysqlCompiler) VisitExcluded(context *CompilerContext, excluded ExcludedClause) string {
+	return fmt.Sprintf("VALUES(%s)", context.Compiler.VisitLabel(context, excluded.Column.Name))
+}
+
+// VisitValues compiles a values list, mysql requiring its rows to be
+// introduced by ROW
+func (MysqlCompiler) VisitValues(context *CompilerContext, values ValuesClause) string {
+	return compileValues(context, values, "ROW")
+}
+
+// VisitHaving compiles a HAVING condition, which may reference a select list alias
+func (MysqlCompiler) VisitHaving(context *CompilerContext, having HavingClause) string {
+	return compileHaving(context, having)
+}
+
+// VisitLimit compiles the LIMIT and OFFSET of a select statement, mysql
+// having no OFFSET without LIMIT it uses the largest count instead
+func (c MysqlCompiler) VisitLimit(context *CompilerContext, limit LimitClause) string {
+	if limit.Count == nil {
+		return fmt.Sprintf("LIMIT 18446744073709551615 OFFSET %d", *limit.Offset)
+	}
+	return c.SQLCompiler.VisitLimit(context, limit)
+}
+
+// VisitInsert compiles a INSERT statement, mysql having no DEFAULT VALUES
+// it inserts an empty list of values instead
+func (c MysqlCompiler) VisitInsert(context *CompilerContext, insert InsertStmt) string {
+	if !insert.defaultValues {
+		return c.SQLCompiler.VisitInsert(context, insert)
+	}
+	if insert.structErr != nil {
+		context.AddError(insert.structErr)
+	}
+	if len(insert.values) > 0 {
+		context.AddError(errors.New("Insert cannot have both values and default values"))
+	}
+	return fmt.Sprintf("INSERT INTO %s() VALUES()", insert.table.Accept(context))
+}
+
+// VisitUpdate compiles a UPDATE statement, with its optional ORDER BY and
+// LIMIT. mysql has no UPDATE ... FROM
+func (c MysqlCompiler) VisitUpdate(context *CompilerContext, update UpdateStmt) string {
+	if update.from != nil {
+		context.AddError(errors.New("UPDATE ... FROM is not supported by mysql"))
+	}
+	orderBy, count := update.orderBy, update.count
+	update.orderBy, update.count = nil, nil
+	sql := c.SQLCompiler.VisitUpdate(context, update)
+	return sql + compileMysqlBounds(context, update.table.Name, orderBy, count)
+}
+
+// VisitDelete compiles a DELETE statement, with its optional ORDER BY and
+// LIMIT
+func (c MysqlCompiler) VisitDelete(context *CompilerContext, delete DeleteStmt) string {
+	orderBy, count := delete.orderBy, delete.count
+	delete.orderBy, delete.count = nil, nil
+	sql := c.SQLCompiler.VisitDelete(context, delete)
+	return sql + compileMysqlBounds(context, delete.table.Name, orderBy, count)
+}
+
+// compileMysqlBounds compiles the ORDER BY and LIMIT of a DELETE or UPDATE
+// statement on the given table
+func compileMysqlBounds(context *CompilerContext, table string, orderBy *OrderByClause, count *int) string {
+	context.DefaultTableName = table
+	defer func() { context.DefaultTableName = "" }()
+
+	sql := ""
+	if orderBy != nil {
+		sql += "\n" + orderBy.Accept(context)
+	}
+	if count != nil {
+		sql += "\n" + compileLimit(context, nil, count)
+	}
+	return sql
+}
+
 // VisitUpsert generates INSERT INTO ... VALUES ... ON DUPLICATE KEY UPDATE ...
 func (MysqlCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) string {
 	var (
@@ -85,29 +284,48 @@ func (MysqlCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) st
 		values   []string
 	)
 
-	for k, v := range upsert.values {
+	for _, k := range sortedKeys(upsert.values) {
 		colNames = append(colNames, context.Compiler.VisitLabel(context, k))
-		context.Binds = append(context.Binds, v)
-		values = append(values, "?")
+		values = append(values, compileNamed(context, k, Bind(upsert.values[k])))
 	}
 
-	updates := []string{}
-	for k, v := range upsert.values {
-		updates = append(updates, fmt.Sprintf(
-			"%s = %s",
-			context.Dialect.Escape(k),
-			"?",
-		))
-		context.Binds = append(context.Binds, v)
+	if upsert.where != nil {
+		context.AddError(errors.New("Upsert WHERE is not supported by mysql"))
+	}
+	if len(upsert.conflict) > 0 || len(upsert.exprs) > 0 {
+		context.AddError(errors.New("Upsert conflict columns are not supported by mysql"))
+	}
+	if upsert.doNothing {
+		context.AddError(errors.New("Upsert DO NOTHING is not supported by mysql"))
+	}
+	if upsert.constraint != "" {
+		context.AddError(errors.New("Upsert ON CONSTRAINT is not supported by mysql"))
 	}
 
+	updates := compileUpsertUpdates(context, upsert)
+
 	sql := fmt.Sprintf(
 		"INSERT INTO %s(%s)\nVALUES(%s)\nON DUPLICATE KEY UPDATE %s",
-		context.Dialect.Escape(upsert.table.Name),
+		context.Compiler.VisitLabel(context, upsert.table.Name),
 		strings.Join(colNames, ", "),
 		strings.Join(values, ", "),
-		strings.Join(updates, ", "),
+		updates,
 	)
 
 	return sql
 }
+
+// VisitLock compiles a FOR UPDATE clause, mysql having no WAIT <n>: its lock
+// timeout is a session setting
+func (c MysqlCompiler) VisitLock(context *CompilerContext, lock LockClause) string {
+	if lock.Timeout != nil {
+		context.AddError(errors.New("FOR UPDATE WAIT is not supported by mysql"))
+	}
+	return c.SQLCompiler.VisitLock(context, lock)
+}
+
+// VisitSelect compiles a SELECT statement, mysql having no GROUP BY ALL: the
+// selected expressions that are not aggregated are grouped explicitly
+func (c MysqlCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt) string {
+	return c.SQLCompiler.VisitSelect(context, expandGroupByAll(selectStmt))
+}
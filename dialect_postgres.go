@@ -1,7 +1,11 @@
 package qb
 
 import (
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -41,6 +45,28 @@ func (d *PostgresDialect) EscapeAll(strings []string) []string {
 	return escapeAll(d, strings[0:])
 }
 
+// Quote wraps the string with the identifier quotes of the dialect, whether
+// its escaping is on or not
+func (d *PostgresDialect) Quote(str string) string {
+	return fmt.Sprintf("\"%s\"", str)
+}
+
+// IsReserved returns whether the string is a reserved word of the dialect,
+// which cannot be used as an unquoted identifier
+func (d *PostgresDialect) IsReserved(str string) bool {
+	return isReservedWord(postgresReservedWords, str)
+}
+
+// QuoteLiteral single-quotes a string literal, doubling the embedded quotes.
+// A string that has backslashes is an escape string (E'...') with doubled
+// backslashes, so it does not depend on standard_conforming_strings
+func (d *PostgresDialect) QuoteLiteral(str string) string {
+	if strings.Contains(str, `\`) {
+		return "E" + quoteString(strings.Replace(str, `\`, `\\`, -1))
+	}
+	return quoteString(str)
+}
+
 // SetEscaping sets the escaping parameter of dialect
 func (d *PostgresDialect) SetEscaping(escaping bool) {
 	d.escaping = escaping
@@ -70,6 +96,31 @@ func (d *PostgresDialect) AutoIncrement(column *ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *PostgresDialect) SupportsUnsigned() bool { return false }
 
+// SupportsTableStorage returns whether driver supports the storage parameters
+// and tablespace of the tables or not
+func (d *PostgresDialect) SupportsTableStorage() bool { return true }
+
+// SupportsInlineComments returns whether driver supports the comments as
+// COMMENT clauses of the CREATE TABLE or not
+func (d *PostgresDialect) SupportsInlineComments() bool { return false }
+
+// SupportsCommentOn returns whether driver supports the comments as COMMENT ON
+// statements following the CREATE TABLE or not
+func (d *PostgresDialect) SupportsCommentOn() bool { return true }
+
+// SupportsAlterForeignKey returns whether driver supports adding the foreign
+// keys of an existing table or not
+func (d *PostgresDialect) SupportsAlterForeignKey() bool { return true }
+
+// SetTransaction returns the statement that sets the isolation level of the
+// transaction, or of the following transactions of the session
+func (d *PostgresDialect) SetTransaction(level IsolationLevel, session bool) (string, error) {
+	if session {
+		return fmt.Sprintf("SET SESSION CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL %s;", level), nil
+	}
+	return setTransactionSQL(level, false), nil
+}
+
 // Driver returns the current driver of dialect
 func (d *PostgresDialect) Driver() string {
 	return "postgres"
@@ -87,54 +138,159 @@ type PostgresCompiler struct {
 
 // VisitBind renders a bounded value
 func (PostgresCompiler) VisitBind(context *CompilerContext, bind BindClause) string {
-	context.Binds = append(context.Binds, bind.Value)
-	return fmt.Sprintf("$%d", len(context.Binds))
+	index := addBind(context, bind.Value)
+	if context.Placeholder != nil {
+		return context.Placeholder(index)
+	}
+	return fmt.Sprintf("$%d", index)
 }
 
-// VisitUpsert generates INSERT INTO ... VALUES ... ON CONFLICT(...) DO UPDATE SET ...
-func (PostgresCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) string {
-	var (
-		colNames []string
-		values   []string
-	)
-	for k, v := range upsert.values {
-		colNames = append(colNames, context.Compiler.VisitLabel(context, k))
-		context.Binds = append(context.Binds, v)
-		values = append(values, fmt.Sprintf("$%d", len(context.Binds)))
+// VisitBinary compiles LEFT <op> RIGHT expressions, postgres having a
+// bitwise XOR operator: #
+func (PostgresCompiler) VisitBinary(context *CompilerContext, binary BinaryExpressionClause) string {
+	return compileBinary(context, binary)
+}
+
+// VisitDistinctOn compiles a DISTINCT ON (<columns>) clause
+func (PostgresCompiler) VisitDistinctOn(context *CompilerContext, distinctOn DistinctOnClause) string {
+	columns := List()
+	for _, col := range distinctOn.Columns {
+		columns.Clauses = append(columns.Clauses, col)
+	}
+	return fmt.Sprintf("DISTINCT ON (%s)", columns.Accept(context))
+}
+
+// VisitIn compiles a IN clause, or a = ANY($1) clause if the ArrayIn option
+// is set and the values are a single array (see isArray)
+func (c PostgresCompiler) VisitIn(context *CompilerContext, in InClause) string {
+	if context.ArrayIn {
+		if list, ok := in.Right.(ListClause); ok && len(list.Clauses) == 1 {
+			if bind, ok := list.Clauses[0].(BindClause); ok && isArray(bind.Value) {
+				op := "= ANY"
+				if in.Op == "NOT IN" {
+					op = "!= ALL"
+				}
+				return fmt.Sprintf("%s %s(%s)", in.Left.Accept(context), op,
+					compileNamed(context, columnBindName(in.Left), bind))
+			}
+		}
+	}
+	return c.SQLCompiler.VisitIn(context, in)
+}
+
+// isArray returns true if a bind value is a slice or an array, not of bytes,
+// or a driver value wrapping one, like the pq.Array() ones: a pointer to a
+// typed array (*pq.Int64Array...) or a pq.GenericArray. The other driver
+// values, like a sql.NullInt64, are single values
+func isArray(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	if _, ok := value.(driver.Valuer); ok {
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		// a wrapper of a single slice field
+		if v.Kind() == reflect.Struct && v.NumField() == 1 && v.Field(0).CanInterface() {
+			v = reflect.ValueOf(v.Field(0).Interface())
+		}
+	}
+	return isSliceValue(v)
+}
+
+// VisitTable returns a table name, optionally escaped and prefixed by ONLY
+func (c PostgresCompiler) VisitTable(context *CompilerContext, table TableElem) string {
+	sql := c.SQLCompiler.VisitTable(context, table)
+	if table.only {
+		sql = "ONLY " + sql
 	}
+	return sql
+}
+
+// VisitCreateTableAs compiles a CREATE TABLE AS statement, with its optional
+// WITH NO DATA
+func (PostgresCompiler) VisitCreateTableAs(context *CompilerContext, create CreateTableAsStmt) string {
+	sql := compileCreateTableAs(context, create)
+	if create.noData {
+		sql += "\nWITH NO DATA"
+	}
+	return sql
+}
 
-	var updates []string
-	for k, v := range upsert.values {
-		context.Binds = append(context.Binds, v)
-		updates = append(updates, fmt.Sprintf(
-			"%s = %s",
-			context.Dialect.Escape(k),
-			fmt.Sprintf("$%d", len(context.Binds)),
-		))
+// VisitWith compiles a WITH clause, which may have data-modifying CTEs
+func (PostgresCompiler) VisitWith(context *CompilerContext, with WithClause) string {
+	return compileWith(context, with)
+}
+
+// VisitLikeAny compiles a match against a list of patterns as
+// LIKE ANY (ARRAY[...]), or ILIKE ANY for the case insensitive version
+func (PostgresCompiler) VisitLikeAny(context *CompilerContext, likeAny LikeAnyClause) string {
+	if len(likeAny.Patterns) == 0 {
+		context.AddError(errors.New("LIKE ANY needs at least one pattern"))
+	}
+	op := "LIKE"
+	if likeAny.CaseInsensitive {
+		op = "ILIKE"
+	}
+	left := likeAny.Left.Accept(context)
+	patterns := []string{}
+	for _, pattern := range likeAny.Patterns {
+		patterns = append(patterns, Bind(pattern).Accept(context))
 	}
+	return fmt.Sprintf("%s %s ANY (ARRAY[%s])", left, op, strings.Join(patterns, ", "))
+}
 
-	var uniqueCols []string
-	for _, c := range upsert.table.PrimaryCols() {
-		uniqueCols = append(uniqueCols, context.Compiler.VisitLabel(context, c.Name))
+// VisitOrdering compiles an order by expression with its direction, or the
+// operator it sorts with
+func (c PostgresCompiler) VisitOrdering(context *CompilerContext, ordering OrderingClause) string {
+	if ordering.Operator == "" {
+		return c.SQLCompiler.VisitOrdering(context, ordering)
+	}
+	sql := fmt.Sprintf("%s USING %s", ordering.Clause.Accept(context), ordering.Operator)
+	if ordering.Nulls != "" {
+		sql += " NULLS " + ordering.Nulls
 	}
+	return sql
+}
 
+// VisitTableSample compiles a '<selectable> TABLESAMPLE <method> (<percentage>)
+// [REPEATABLE (<seed>)]' clause
+func (PostgresCompiler) VisitTableSample(context *CompilerContext, sample TableSampleClause) string {
 	sql := fmt.Sprintf(
-		"INSERT INTO %s(%s)\nVALUES(%s)\nON CONFLICT (%s) DO UPDATE SET %s",
-		context.Compiler.VisitLabel(context, upsert.table.Name),
-		strings.Join(colNames, ", "),
-		strings.Join(values, ", "),
-		strings.Join(uniqueCols, ", "),
-		strings.Join(updates, ", "))
-
-	var returning []string
-	for _, r := range upsert.returning {
-		returning = append(returning, context.Compiler.VisitLabel(context, r.Name))
-	}
-	if len(upsert.returning) > 0 {
-		sql += fmt.Sprintf(
-			"RETURNING %s",
-			strings.Join(returning, ", "),
-		)
+		"%s TABLESAMPLE %s (%s)",
+		sample.Selectable.Accept(context),
+		sample.Method,
+		strconv.FormatFloat(sample.Percentage, 'f', -1, 64),
+	)
+	if sample.Seed != nil {
+		sql += fmt.Sprintf(" REPEATABLE (%d)", *sample.Seed)
 	}
 	return sql
 }
+
+// VisitUpsert generates INSERT INTO ... VALUES ... ON CONFLICT(...) DO UPDATE SET ...
+func (PostgresCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) string {
+	return compileOnConflictUpsert(context, upsert)
+}
+
+// VisitWindow compiles a window function call, postgres supporting neither
+// IGNORE NULLS nor RESPECT NULLS
+func (c PostgresCompiler) VisitWindow(context *CompilerContext, window WindowClause) string {
+	if window.nulls != "" {
+		context.AddError(fmt.Errorf("%s is not supported by postgres", window.nulls))
+	}
+	return c.SQLCompiler.VisitWindow(context, window)
+}
+
+// VisitLock compiles a FOR UPDATE clause, postgres having no WAIT <n>: its lock
+// timeout is a session setting
+func (c PostgresCompiler) VisitLock(context *CompilerContext, lock LockClause) string {
+	if lock.Timeout != nil {
+		context.AddError(errors.New("FOR UPDATE WAIT is not supported by postgres"))
+	}
+	return c.SQLCompiler.VisitLock(context, lock)
+}
+
+// VisitSelect compiles a SELECT statement, postgres having no GROUP BY ALL: the
+// selected expressions that are not aggregated are grouped explicitly
+func (c PostgresCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt) string {
+	return c.SQLCompiler.VisitSelect(context, expandGroupByAll(selectStmt))
+}
@@ -1,6 +1,7 @@
 package qb
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -38,6 +39,23 @@ func (d *SqliteDialect) EscapeAll(strings []string) []string {
 	return escapeAll(d, strings[0:])
 }
 
+// Quote wraps the string with the identifier quotes of the dialect, whether
+// its escaping is on or not
+func (d *SqliteDialect) Quote(str string) string {
+	return fmt.Sprintf("\"%s\"", str)
+}
+
+// IsReserved returns whether the string is a reserved word of the dialect,
+// which cannot be used as an unquoted identifier
+func (d *SqliteDialect) IsReserved(str string) bool {
+	return isReservedWord(sqliteReservedWords, str)
+}
+
+// QuoteLiteral single-quotes a string literal, doubling the embedded quotes
+func (d *SqliteDialect) QuoteLiteral(str string) string {
+	return quoteString(str)
+}
+
 // SetEscaping sets the escaping parameter of dialect
 func (d *SqliteDialect) SetEscaping(escaping bool) {
 	d.escaping = escaping
@@ -59,6 +77,28 @@ func (d *SqliteDialect) AutoIncrement(column *ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *SqliteDialect) SupportsUnsigned() bool { return false }
 
+// SupportsTableStorage returns whether driver supports the storage parameters
+// and tablespace of the tables or not
+func (d *SqliteDialect) SupportsTableStorage() bool { return false }
+
+// SupportsInlineComments returns whether driver supports the comments as
+// COMMENT clauses of the CREATE TABLE or not
+func (d *SqliteDialect) SupportsInlineComments() bool { return false }
+
+// SupportsCommentOn returns whether driver supports the comments as COMMENT ON
+// statements following the CREATE TABLE or not
+func (d *SqliteDialect) SupportsCommentOn() bool { return false }
+
+// SupportsAlterForeignKey returns whether driver supports adding the foreign
+// keys of an existing table or not
+func (d *SqliteDialect) SupportsAlterForeignKey() bool { return false }
+
+// SetTransaction returns the statement that sets the isolation level of the
+// transaction, or of the following transactions of the session
+func (d *SqliteDialect) SetTransaction(level IsolationLevel, session bool) (string, error) {
+	return "", errors.New("SET TRANSACTION ISOLATION LEVEL is not supported by sqlite")
+}
+
 // Driver returns the current driver of dialect
 func (d *SqliteDialect) Driver() string {
 	return "sqlite3"
@@ -74,16 +114,83 @@ type SqliteCompiler struct {
 	SQLCompiler
 }
 
+// VisitDefaultValue reports an error, sqlite has no DEFAULT keyword in VALUES
+func (SqliteCompiler) VisitDefaultValue(context *CompilerContext, def DefaultValueClause) string {
+	context.AddError(errors.New("DEFAULT value is not supported by sqlite"))
+	return ""
+}
+
+// VisitLock reports an error, sqlite has no row locking
+func (SqliteCompiler) VisitLock(context *CompilerContext, lock LockClause) string {
+	context.AddError(fmt.Errorf("FOR %s is not supported by sqlite", lock.Strength))
+	return ""
+}
+
+// VisitMerge reports an error, sqlite has no MERGE
+func (SqliteCompiler) VisitMerge(context *CompilerContext, merge MergeStmt) string {
+	context.AddError(errors.New("MERGE is not supported by sqlite"))
+	return ""
+}
+
+// VisitInto reports an error, sqlite has no SELECT INTO
+func (SqliteCompiler) VisitInto(context *CompilerContext, into IntoClause) string {
+	context.AddError(errors.New("SELECT INTO is not supported by sqlite"))
+	return ""
+}
+
+// VisitValues reports an error, sqlite cannot name the columns of a values
+// list aliased in a FROM clause
+func (SqliteCompiler) VisitValues(context *CompilerContext, values ValuesClause) string {
+	context.AddError(errors.New("VALUES list in FROM is not supported by sqlite"))
+	return ""
+}
+
+// VisitHaving compiles a HAVING condition, which may reference a select list alias
+func (SqliteCompiler) VisitHaving(context *CompilerContext, having HavingClause) string {
+	return compileHaving(context, having)
+}
+
+// VisitCompound compiles a compound select statement. Sqlite does not
+// accept parenthesized members, which therefore cannot have their own
+// ORDER BY or LIMIT
+func (SqliteCompiler) VisitCompound(context *CompilerContext, compound CompoundStmt) string {
+	for _, sel := range compound.selects {
+		if sel.orderBy != nil || sel.offset != nil || sel.count != nil {
+			context.AddError(fmt.Errorf(
+				"%s members cannot have an ORDER BY or a LIMIT in sqlite", compound.operator))
+			break
+		}
+	}
+	return compileCompound(context, compound, false)
+}
+
+// VisitLimit compiles the LIMIT and OFFSET of a select statement, sqlite
+// having no OFFSET without LIMIT it uses a negative count, which means no
+// limit
+func (c SqliteCompiler) VisitLimit(context *CompilerContext, limit LimitClause) string {
+	if limit.Count == nil {
+		return fmt.Sprintf("LIMIT -1 OFFSET %d", *limit.Offset)
+	}
+	return c.SQLCompiler.VisitLimit(context, limit)
+}
+
 // VisitUpsert generates the following sql: REPLACE INTO ... VALUES ...
+// If the update values or condition, the conflict columns or DO NOTHING are
+// set, it generates a INSERT INTO ... VALUES ... ON CONFLICT(...) ... instead
 func (SqliteCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) string {
+	if upsert.constraint != "" {
+		context.AddError(errors.New("Upsert ON CONSTRAINT is not supported by sqlite"))
+	}
+	if len(upsert.set) > 0 || upsert.where != nil || len(upsert.conflict) > 0 || len(upsert.exprs) > 0 || upsert.doNothing {
+		return compileOnConflictUpsert(context, upsert)
+	}
 	var (
 		colNames []string
 		values   []string
 	)
-	for k, v := range upsert.values {
+	for _, k := range sortedKeys(upsert.values) {
 		colNames = append(colNames, context.Compiler.VisitLabel(context, k))
-		context.Binds = append(context.Binds, v)
-		values = append(values, "?")
+		values = append(values, compileNamed(context, k, Bind(upsert.values[k])))
 	}
 
 	sql := fmt.Sprintf(
@@ -95,3 +202,18 @@ func (SqliteCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) s
 
 	return sql
 }
+
+// VisitWindow compiles a window function call, sqlite supporting neither
+// IGNORE NULLS nor RESPECT NULLS
+func (c SqliteCompiler) VisitWindow(context *CompilerContext, window WindowClause) string {
+	if window.nulls != "" {
+		context.AddError(fmt.Errorf("%s is not supported by sqlite", window.nulls))
+	}
+	return c.SQLCompiler.VisitWindow(context, window)
+}
+
+// VisitSelect compiles a SELECT statement, sqlite having no GROUP BY ALL: the
+// selected expressions that are not aggregated are grouped explicitly
+func (c SqliteCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt) string {
+	return c.SQLCompiler.VisitSelect(context, expandGroupByAll(selectStmt))
+}
@@ -24,6 +24,13 @@ func (suite *DialectTestSuite) SetupTest() {
 func (suite *DialectTestSuite) TestDefaultDialect() {
 	assert.Implements(suite.T(), (*Compiler)(nil), suite.def.GetCompiler())
 	assert.Equal(suite.T(), false, suite.def.SupportsUnsigned())
+	assert.Equal(suite.T(), false, suite.def.SupportsTableStorage())
+	assert.Equal(suite.T(), false, suite.def.SupportsInlineComments())
+	assert.Equal(suite.T(), false, suite.def.SupportsCommentOn())
+	assert.Equal(suite.T(), true, suite.def.SupportsAlterForeignKey())
+	assert.Equal(suite.T(), "\"order\"", suite.def.Quote("order"))
+	assert.Equal(suite.T(), true, suite.def.IsReserved("Order"))
+	assert.Equal(suite.T(), false, suite.def.IsReserved("key"))
 	assert.Equal(suite.T(), "test", suite.def.Escape("test"))
 	assert.Equal(suite.T(), false, suite.def.Escaping())
 	suite.def.SetEscaping(true)
@@ -31,6 +38,7 @@ func (suite *DialectTestSuite) TestDefaultDialect() {
 	assert.Equal(suite.T(), "`test`", suite.def.Escape("test"))
 	assert.Equal(suite.T(), []string{"`test`"}, suite.def.EscapeAll([]string{"test"}))
 	assert.Equal(suite.T(), "", suite.def.Driver())
+	assert.Equal(suite.T(), `'it''s a \ test'`, suite.def.QuoteLiteral(`it's a \ test`))
 
 	autoincCol := Column("id", Int()).PrimaryKey().AutoIncrement()
 	assert.Equal(suite.T(),
@@ -41,6 +49,16 @@ func (suite *DialectTestSuite) TestDefaultDialect() {
 
 func (suite *DialectTestSuite) TestMysqlDialect() {
 	assert.Equal(suite.T(), true, suite.mysql.SupportsUnsigned())
+	assert.Equal(suite.T(), false, suite.mysql.SupportsTableStorage())
+	assert.Equal(suite.T(), true, suite.mysql.SupportsInlineComments())
+	assert.Equal(suite.T(), false, suite.mysql.SupportsCommentOn())
+	assert.Equal(suite.T(), true, suite.mysql.SupportsAlterForeignKey())
+	sql, err := suite.mysql.SetTransaction(Serializable, true)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "SET SESSION TRANSACTION ISOLATION LEVEL SERIALIZABLE;", sql)
+	assert.Equal(suite.T(), "`order`", suite.mysql.Quote("order"))
+	assert.Equal(suite.T(), true, suite.mysql.IsReserved("Order"))
+	assert.Equal(suite.T(), true, suite.mysql.IsReserved("key"))
 	assert.Equal(suite.T(), "test", suite.mysql.Escape("test"))
 	assert.Equal(suite.T(), false, suite.mysql.Escaping())
 	suite.mysql.SetEscaping(true)
@@ -48,10 +66,22 @@ func (suite *DialectTestSuite) TestMysqlDialect() {
 	assert.Equal(suite.T(), "`test`", suite.mysql.Escape("test"))
 	assert.Equal(suite.T(), []string{"`test`"}, suite.mysql.EscapeAll([]string{"test"}))
 	assert.Equal(suite.T(), "mysql", suite.mysql.Driver())
+	assert.Equal(suite.T(), `'it''s'`, suite.mysql.QuoteLiteral(`it's`))
+	assert.Equal(suite.T(), `'it''s a \\ test'`, suite.mysql.QuoteLiteral(`it's a \ test`))
 }
 
 func (suite *DialectTestSuite) TestPostgresDialect() {
 	assert.Equal(suite.T(), false, suite.postgres.SupportsUnsigned())
+	assert.Equal(suite.T(), true, suite.postgres.SupportsTableStorage())
+	assert.Equal(suite.T(), false, suite.postgres.SupportsInlineComments())
+	assert.Equal(suite.T(), true, suite.postgres.SupportsCommentOn())
+	assert.Equal(suite.T(), true, suite.postgres.SupportsAlterForeignKey())
+	sql, err := suite.postgres.SetTransaction(Serializable, true)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "SET SESSION CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL SERIALIZABLE;", sql)
+	assert.Equal(suite.T(), "\"order\"", suite.postgres.Quote("order"))
+	assert.Equal(suite.T(), true, suite.postgres.IsReserved("Order"))
+	assert.Equal(suite.T(), false, suite.postgres.IsReserved("key"))
 	assert.Equal(suite.T(), "test", suite.postgres.Escape("test"))
 	assert.Equal(suite.T(), false, suite.postgres.Escaping())
 	suite.postgres.SetEscaping(true)
@@ -59,6 +89,8 @@ func (suite *DialectTestSuite) TestPostgresDialect() {
 	assert.Equal(suite.T(), "\"test\"", suite.postgres.Escape("test"))
 	assert.Equal(suite.T(), []string{"\"test\""}, suite.postgres.EscapeAll([]string{"test"}))
 	assert.Equal(suite.T(), "postgres", suite.postgres.Driver())
+	assert.Equal(suite.T(), `'it''s'`, suite.postgres.QuoteLiteral(`it's`))
+	assert.Equal(suite.T(), `E'it''s a \\ test'`, suite.postgres.QuoteLiteral(`it's a \ test`))
 
 	col := Column("autoinc", Int()).AutoIncrement()
 	assert.Equal(suite.T(), "SERIAL", suite.postgres.AutoIncrement(&col))
@@ -70,8 +102,38 @@ func (suite *DialectTestSuite) TestPostgresDialect() {
 	assert.Equal(suite.T(), "SMALLSERIAL", suite.postgres.AutoIncrement(&col))
 }
 
+func (suite *DialectTestSuite) TestPostgresPlaceholders() {
+	orders := Table("orders", Column("id", Int()), Column("user_id", Int()), Column("total", Int()))
+
+	// the placeholders are numbered by the binds of the statement being
+	// compiled, so each statement starts over at $1
+	for i := 0; i < 2; i++ {
+		statement := Select(orders.C("user_id"), Sum(orders.C("total"))).
+			From(orders).
+			Where(orders.C("id").Gt(10), orders.C("total").Lt(1000)).
+			GroupBy(orders.C("user_id")).
+			Having(Sum(orders.C("total")), ">", 100).
+			Build(suite.postgres)
+		assert.Equal(suite.T(), "SELECT user_id, SUM(total)\nFROM orders\nWHERE (id > $1 AND total < $2)\nGROUP BY user_id\nHAVING SUM(total) > $3;", statement.SQL())
+		assert.Equal(suite.T(), []interface{}{10, 1000, 100}, statement.Bindings())
+	}
+
+	statement := Insert(orders).Values(map[string]interface{}{"id": 1, "user_id": 2, "total": 3}).Build(suite.postgres)
+	assert.Equal(suite.T(), "INSERT INTO orders(id, total, user_id)\nVALUES($1, $2, $3);", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{1, 3, 2}, statement.Bindings())
+}
+
 func (suite *DialectTestSuite) TestSqliteDialect() {
 	assert.Equal(suite.T(), false, suite.sqlite.SupportsUnsigned())
+	assert.Equal(suite.T(), false, suite.sqlite.SupportsTableStorage())
+	assert.Equal(suite.T(), false, suite.sqlite.SupportsInlineComments())
+	assert.Equal(suite.T(), false, suite.sqlite.SupportsCommentOn())
+	assert.Equal(suite.T(), false, suite.sqlite.SupportsAlterForeignKey())
+	_, err := suite.sqlite.SetTransaction(Serializable, false)
+	assert.EqualError(suite.T(), err, "SET TRANSACTION ISOLATION LEVEL is not supported by sqlite")
+	assert.Equal(suite.T(), "\"order\"", suite.sqlite.Quote("order"))
+	assert.Equal(suite.T(), true, suite.sqlite.IsReserved("Order"))
+	assert.Equal(suite.T(), false, suite.sqlite.IsReserved("key"))
 	assert.Equal(suite.T(), "test", suite.sqlite.Escape("test"))
 	assert.Equal(suite.T(), false, suite.sqlite.Escaping())
 	suite.sqlite.SetEscaping(true)
@@ -79,6 +141,7 @@ func (suite *DialectTestSuite) TestSqliteDialect() {
 	assert.Equal(suite.T(), "test", suite.sqlite.Escape("test"))
 	assert.Equal(suite.T(), []string{"test"}, suite.sqlite.EscapeAll([]string{"test"}))
 	assert.Equal(suite.T(), "sqlite3", suite.sqlite.Driver())
+	assert.Equal(suite.T(), `'it''s a \ test'`, suite.sqlite.QuoteLiteral(`it's a \ test`))
 }
 
 func TestDialectTestSuite(t *testing.T) {
@@ -0,0 +1,28 @@
+package qb
+
+// Global registry of named query fragments
+var FragmentRegistry = make(map[string]Clause)
+
+// RegisterFragment adds a named fragment to the registry, or replaces it
+// The queries that use the fragment get the new definition the next time
+// they are compiled
+func RegisterFragment(name string, clause Clause) {
+	FragmentRegistry[name] = clause
+}
+
+// Fragment returns a FragmentClause, a reference to a registered fragment
+// Fragment("active") is expanded to the clause registered as "active" when
+// the statement is compiled
+func Fragment(name string) FragmentClause {
+	return FragmentClause{Name: name}
+}
+
+// FragmentClause is a reference to a registered fragment
+type FragmentClause struct {
+	Name string
+}
+
+// Accept calls the compiler VisitFragment function
+func (c FragmentClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitFragment(context, c)
+}
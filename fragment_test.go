@@ -0,0 +1,37 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFragment(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("active", Boolean()),
+		Column("deleted", Boolean()),
+	)
+	postgres := NewDialect("postgres")
+
+	RegisterFragment("users.visible", And(users.C("active").Eq(true), users.C("deleted").Eq(false)))
+	defer delete(FragmentRegistry, "users.visible")
+
+	byID := Select(users.C("id")).From(users).Where(users.C("id").Eq(5), Fragment("users.visible"))
+	count := Select(Count(users.C("id"))).From(users).Where(Fragment("users.visible"))
+
+	statement := byID.Build(postgres)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE (id = $1 AND (active = $2 AND deleted = $3));", statement.SQL())
+	assert.Equal(t, []interface{}{5, true, false}, statement.Bindings())
+
+	statement = count.Build(postgres)
+	assert.Equal(t, "SELECT COUNT(id)\nFROM users\nWHERE (active = $1 AND deleted = $2);", statement.SQL())
+	assert.Equal(t, []interface{}{true, false}, statement.Bindings())
+
+	// changing the definition updates the queries
+	RegisterFragment("users.visible", users.C("active").Eq(true))
+	assert.Equal(t, "SELECT COUNT(id)\nFROM users\nWHERE active = $1;", count.Build(postgres).SQL())
+
+	_, err := BuildStmt(NewCompilerContext(postgres), Select(users.C("id")).From(users).Where(Fragment("nope")))
+	assert.EqualError(t, err, "No such fragment: nope")
+}
@@ -0,0 +1,33 @@
+package qb
+
+import "strings"
+
+// Func generates a function call, whose arguments are bound unless they are
+// clauses: Func("lower", users.C("email"))
+// A schema-qualified name has each of its parts escaped separately:
+// Func("billing.next_invoice_id", 5) gives "billing"."next_invoice_id"($1)
+// An unqualified name is rendered as is, for the built-in functions
+func Func(name string, args ...interface{}) FuncClause {
+	clauses := []Clause{}
+	for _, arg := range args {
+		clauses = append(clauses, GetClauseFrom(arg))
+	}
+	return FuncClause{Name: name, Args: clauses}
+}
+
+// FuncClause is a function call
+type FuncClause struct {
+	Name string
+	Args []Clause
+}
+
+// Parts returns the parts of the function name: the schema, if any, and the
+// function name
+func (c FuncClause) Parts() []string {
+	return strings.Split(c.Name, ".")
+}
+
+// Accept calls the compiler VisitFunc method
+func (c FuncClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitFunc(context, c)
+}
@@ -0,0 +1,32 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFunc(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+
+	assert.Equal(t, "lower(users.email)", asDefSQL(Func("lower", users.C("email"))))
+	assert.Equal(t, "now()", asDefSQL(Func("now")))
+
+	postgres := NewDialect("postgres")
+	postgres.SetEscaping(true)
+
+	sql, binds := asSQLBinds(Func("billing.next_invoice_id", users.C("id"), 5), postgres)
+	assert.Equal(t, "\"billing\".\"next_invoice_id\"(\"users\".\"id\", $1)", sql)
+	assert.Equal(t, []interface{}{5}, binds)
+
+	mysql := NewDialect("mysql")
+	mysql.SetEscaping(true)
+	assert.Equal(t, "`billing`.`next_invoice_id`(?)", asSQL(Func("billing.next_invoice_id", 5), mysql))
+
+	statement := Select(As(Func("audit.fingerprint", users.C("email")), "fp")).
+		From(users).
+		Where(Eq(Func("lower", users.C("email")), "al@pacino.com")).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT audit.fingerprint(email) AS fp\n"+
+		"FROM users\n"+
+		"WHERE lower(email) = $1;", statement.SQL())
+}
@@ -1,36 +1,131 @@
 package qb
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // Insert generates an insert statement and returns it
 // Insert(usersTable).Values(map[string]interface{}{"id": 1})
 func Insert(table TableElem) InsertStmt {
 	return InsertStmt{
 		table:     table,
 		values:    map[string]interface{}{},
-		returning: []ColumnElem{},
+		returning: []Clause{},
 	}
 }
 
 // InsertStmt is the base struct for any insert statements
 type InsertStmt struct {
-	table     TableElem
-	values    map[string]interface{}
-	returning []ColumnElem
+	table         TableElem
+	values        map[string]interface{}
+	defaultValues bool
+	returning     []Clause
+	with          WithClause
+	sel           *SelectStmt
+	selColumns    []string
+	columns       []string
+	structErr     error
 }
 
 // Values accepts map[string]interface{} and forms the values map of insert statement
+// A value can be a Clause, like DefaultValue(), which is then inlined
+// instead of being bound
 func (s InsertStmt) Values(values map[string]interface{}) InsertStmt {
+	merged := map[string]interface{}{}
+	for k, v := range s.values {
+		merged[k] = v
+	}
 	for k, v := range values {
-		s.values[k] = v
+		merged[k] = v
 	}
+	s.values = merged
 	return s
 }
 
-// Returning accepts the column names as strings and forms the returning array of insert statement
-// NOTE: Please use it in only postgres dialect, otherwise it'll crash
-func (s InsertStmt) Returning(cols ...ColumnElem) InsertStmt {
-	for _, c := range cols {
-		s.returning = append(s.returning, c)
+// StructValues adds the fields of a struct, or of a pointer to a struct, to
+// the values of the statement. The fields are mapped to the columns like
+// ScanOne does, the ones that map no column of the table are ignored.
+// The fields are merged with the values given by Values, before or after,
+// the last given value of a column winning. The columns are inserted in the
+// order of the struct fields, then the other values in alphabetical order,
+// so the SQL does not depend on the values
+// A value that is not a struct is reported when the statement is compiled
+func (s InsertStmt) StructValues(value interface{}) InsertStmt {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Struct {
+		s.structErr = fmt.Errorf("StructValues expects a struct, got %T", value)
+		return s
+	}
+	values := map[string]interface{}{}
+	columns := append([]string{}, s.columns...)
+	for _, field := range structFields(v.Type()) {
+		if _, ok := s.table.Columns[field.column]; !ok {
+			continue
+		}
+		if !containsString(columns, field.column) {
+			columns = append(columns, field.column)
+		}
+		values[field.column] = v.Field(field.index).Interface()
+	}
+	s = s.Values(values)
+	s.columns = columns
+	return s
+}
+
+// valueColumns returns the names of the inserted columns: the struct ones
+// in declaration order, then the others in alphabetical order
+func (s InsertStmt) valueColumns() []string {
+	columns := append([]string{}, s.columns...)
+	for _, name := range sortedKeys(s.values) {
+		if !containsString(s.columns, name) {
+			columns = append(columns, name)
+		}
 	}
+	return columns
+}
+
+// Select makes the statement insert the rows of a select statement, in the
+// given columns, or in all the table columns if none is given.
+// It cannot be combined with Values
+func (s InsertStmt) Select(sel SelectStmt, columns ...string) InsertStmt {
+	s.sel = &sel
+	s.selColumns = columns
+	return s
+}
+
+// DefaultValues makes the statement insert a row made of the columns default
+// values only. It cannot be combined with Values
+func (s InsertStmt) DefaultValues() InsertStmt {
+	s.defaultValues = true
+	return s
+}
+
+// DefaultValue returns a DefaultValueClause, to be used as an insert value
+func DefaultValue() DefaultValueClause {
+	return DefaultValueClause{}
+}
+
+// DefaultValueClause is the DEFAULT keyword, that inserts the column
+// default value
+type DefaultValueClause struct{}
+
+// Accept calls the compiler VisitDefaultValue function
+func (c DefaultValueClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitDefaultValue(context, c)
+}
+
+// Returning accepts the columns or expressions, possibly aliased with As(),
+// and forms the returning array of the statement
+// NOTE: Please use it in only postgres dialect, otherwise it'll crash
+func (s InsertStmt) Returning(cols ...Clause) InsertStmt {
+	s.returning = append(append([]Clause{}, s.returning...), cols...)
+	return s
+}
+
+// With appends common table expressions to the statement
+func (s InsertStmt) With(ctes ...CTEClause) InsertStmt {
+	s.with.CTEs = append(append([]CTEClause{}, s.with.CTEs...), ctes...)
 	return s
 }
 
@@ -40,11 +135,31 @@ func (s InsertStmt) Accept(context *CompilerContext) string {
 }
 
 // Build generates a statement out of InsertStmt object
+// It panics if the statement cannot be compiled
 func (s InsertStmt) Build(dialect Dialect) *Stmt {
-	statement := Statement()
-	context := NewCompilerContext(dialect)
-	statement.AddSQLClause(s.Accept(context))
-	statement.AddBinding(context.Binds...)
+	return mustBuildStmt(dialect, s)
+}
+
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s InsertStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
+
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s InsertStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s InsertStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
 
-	return statement
+// String compiles the statement with the default dialect, for inspection
+func (s InsertStmt) String() string {
+	return stmtString(s)
 }
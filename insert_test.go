@@ -63,3 +63,153 @@ func TestInsert(t *testing.T) {
 	assert.Contains(t, statement.SQL(), "RETURNING \"id\", \"email\";")
 	assert.Contains(t, statement.Bindings(), "9883cf81-3b56-4151-ae4e-3903c5bc436d", "al@pacino.com")
 }
+
+func TestInsertStableOrder(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("name", Varchar()),
+		Column("age", Int()),
+	)
+	ins := Insert(users).Values(map[string]interface{}{
+		"name":  "Al",
+		"id":    1,
+		"email": "al@pacino.com",
+		"age":   42,
+	})
+
+	for i := 0; i < 10; i++ {
+		statement := ins.Build(NewDialect("postgres"))
+		assert.Equal(t, "INSERT INTO users(age, email, id, name)\nVALUES($1, $2, $3, $4);", statement.SQL())
+		assert.Equal(t, []interface{}{42, "al@pacino.com", 1, "Al"}, statement.Bindings())
+	}
+
+	upsert := Upsert(users).Values(map[string]interface{}{"name": "Al", "id": 1, "age": 42})
+	for i := 0; i < 10; i++ {
+		statement := upsert.Build(NewDialect("mysql"))
+		assert.Equal(t, "INSERT INTO users(age, id, name)\nVALUES(?, ?, ?)\nON DUPLICATE KEY UPDATE age = ?, id = ?, name = ?;", statement.SQL())
+		assert.Equal(t, []interface{}{42, 1, "Al", 42, 1, "Al"}, statement.Bindings())
+	}
+}
+
+func TestInsertDefaultValue(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Varchar().Size(36)),
+		Column("email", Varchar()).Unique(),
+	)
+
+	ins := Insert(users).Values(map[string]interface{}{
+		"id":    DefaultValue(),
+		"email": "al@pacino.com",
+	})
+
+	statement := ins.Build(NewDialect("postgres"))
+	assert.Contains(t, []string{
+		"INSERT INTO users(id, email)\nVALUES(DEFAULT, $1);",
+		"INSERT INTO users(email, id)\nVALUES($1, DEFAULT);",
+	}, statement.SQL())
+	assert.Equal(t, []interface{}{"al@pacino.com"}, statement.Bindings())
+
+	statement = ins.Build(NewDialect("mysql"))
+	assert.Contains(t, []string{
+		"INSERT INTO users(id, email)\nVALUES(DEFAULT, ?);",
+		"INSERT INTO users(email, id)\nVALUES(?, DEFAULT);",
+	}, statement.SQL())
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("sqlite3")), ins)
+	assert.EqualError(t, err, "DEFAULT value is not supported by sqlite")
+}
+
+func TestInsertDefaultValues(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey().AutoIncrement(),
+	)
+
+	ins := Insert(users).DefaultValues()
+
+	assert.Equal(t, "INSERT INTO users DEFAULT VALUES;", ins.Build(NewDialect("sqlite3")).SQL())
+	assert.Equal(t, "INSERT INTO users DEFAULT VALUES\nRETURNING id;", ins.Returning(users.C("id")).Build(NewDialect("postgres")).SQL())
+	assert.Equal(t, "INSERT INTO users() VALUES();", ins.Build(NewDialect("mysql")).SQL())
+
+	_, err := BuildStmt(
+		NewCompilerContext(NewDialect("postgres")),
+		ins.Values(map[string]interface{}{"id": 1}))
+	assert.EqualError(t, err, "Insert cannot have both values and default values")
+}
+
+func TestInsertReturningAlias(t *testing.T) {
+	postgres := NewDialect("postgres")
+	postgres.SetEscaping(true)
+
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey().AutoIncrement(),
+		Column("created_at", Timestamp()),
+	)
+
+	statement := Insert(users).
+		DefaultValues().
+		Returning(As(users.C("id"), "new_id"), users.C("created_at")).
+		Build(postgres)
+	assert.Equal(t, "INSERT INTO \"users\" DEFAULT VALUES\nRETURNING \"id\" AS \"new_id\", \"created_at\";", statement.SQL())
+
+	statement = Delete(users).
+		Where(users.C("id").Eq(1)).
+		Returning(As(users.C("id"), "old_id")).
+		Build(postgres)
+	assert.Equal(t, "DELETE FROM \"users\"\nWHERE \"users\".\"id\" = $1\nRETURNING \"id\" AS \"old_id\";", statement.SQL())
+
+	statement = Update(users).
+		Values(map[string]interface{}{"created_at": SQLText("now()")}).
+		Returning(As(users.C("created_at"), "updated")).
+		Build(postgres)
+	assert.Equal(t, "UPDATE \"users\"\nSET \"created_at\" = now()\nRETURNING \"created_at\" AS \"updated\";", statement.SQL())
+}
+
+func TestInsertStructValues(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("full_name", Varchar()),
+		Column("created_at", Timestamp()),
+	)
+
+	type user struct {
+		ID       int `db:"id"`
+		FullName string
+		Email    string
+		Password string // not a column
+		secret   string
+	}
+
+	al := user{ID: 1, FullName: "Al Pacino", Email: "al@pacino.com", Password: "x", secret: "y"}
+
+	statement := Insert(users).StructValues(al).Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO users(id, full_name, email)\nVALUES($1, $2, $3);", statement.SQL())
+	assert.Equal(t, []interface{}{1, "Al Pacino", "al@pacino.com"}, statement.Bindings())
+
+	// the struct columns come first, the other values are sorted
+	statement = Insert(users).
+		Values(map[string]interface{}{"created_at": "2016-01-01", "email": "-"}).
+		StructValues(&al).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO users(id, full_name, email, created_at)\nVALUES($1, $2, $3, $4);", statement.SQL())
+	assert.Equal(t, []interface{}{1, "Al Pacino", "al@pacino.com", "2016-01-01"}, statement.Bindings())
+
+	// the values given after override the struct ones, the struct columns
+	// keeping their place
+	statement = Insert(users).
+		StructValues(al).
+		Values(map[string]interface{}{"full_name": "Alfredo", "created_at": "2016-01-01"}).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO users(id, full_name, email, created_at)\nVALUES($1, $2, $3, $4);", statement.SQL())
+	assert.Equal(t, []interface{}{1, "Alfredo", "al@pacino.com", "2016-01-01"}, statement.Bindings())
+
+	_, _, err := Insert(users).StructValues(map[string]interface{}{}).ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "StructValues expects a struct, got map[string]interface {}")
+	assert.Panics(t, func() { Insert(users).StructValues(1).Build(NewDialect("postgres")) })
+}
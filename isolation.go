@@ -0,0 +1,114 @@
+package qb
+
+import "fmt"
+
+// IsolationLevel is a transaction isolation level
+type IsolationLevel string
+
+// The transaction isolation levels
+const (
+	ReadUncommitted IsolationLevel = "READ UNCOMMITTED"
+	ReadCommitted   IsolationLevel = "READ COMMITTED"
+	RepeatableRead  IsolationLevel = "REPEATABLE READ"
+	Serializable    IsolationLevel = "SERIALIZABLE"
+)
+
+// SetTransaction generates a statement that sets the isolation level of a
+// transaction. On postgres, it sets the one of the current transaction and
+// must be its first statement: tx.Exec(SetTransaction(Serializable))
+// On mysql, it sets the one of the next transaction and must be executed
+// before it begins
+// NOTE: sqlite does not support it
+func SetTransaction(level IsolationLevel) SetTransactionStmt {
+	return SetTransactionStmt{level: level}
+}
+
+// SetTransactionStmt is the base struct for building isolation level
+// statements
+type SetTransactionStmt struct {
+	level   IsolationLevel
+	session bool
+}
+
+// Session sets the isolation level of all the following transactions of the
+// session instead of the current one
+func (s SetTransactionStmt) Session() SetTransactionStmt {
+	s.session = true
+	return s
+}
+
+// compile returns the isolation level statement for the given dialect, or
+// an error if the level is unknown or the dialect does not support it
+func (s SetTransactionStmt) compile(dialect Dialect) (string, error) {
+	switch s.level {
+	case ReadUncommitted, ReadCommitted, RepeatableRead, Serializable:
+	default:
+		return "", fmt.Errorf("Unknown isolation level '%s'", s.level)
+	}
+
+	return dialect.SetTransaction(s.level, s.session)
+}
+
+// setTransactionSQL returns the standard isolation level statement
+func setTransactionSQL(level IsolationLevel, session bool) string {
+	if session {
+		return fmt.Sprintf("SET SESSION TRANSACTION ISOLATION LEVEL %s;", level)
+	}
+	return fmt.Sprintf("SET TRANSACTION ISOLATION LEVEL %s;", level)
+}
+
+// SQL returns the isolation level statement for the given dialect
+// It panics if the level is unknown or the dialect does not support it
+func (s SetTransactionStmt) SQL(dialect Dialect) string {
+	sql, err := s.compile(dialect)
+	if err != nil {
+		panic(err)
+	}
+	return sql
+}
+
+// String returns the isolation level statement for the default dialect, for
+// inspection
+func (s SetTransactionStmt) String() string {
+	sql, err := s.compile(NewDialect("default"))
+	if err != nil {
+		return fmt.Sprintf("<invalid statement: %v>", err)
+	}
+	return sql
+}
+
+// ToSQL returns the isolation level statement for the given dialect, which
+// has no bindings. Contrary to Build, it returns the errors
+func (s SetTransactionStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	sql, err := s.compile(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, nil, nil
+}
+
+// Validate returns the issues found in the statement for the given dialect
+func (s SetTransactionStmt) Validate(dialect Dialect) []error {
+	if _, err := s.compile(dialect); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// CanonicalSQL returns the isolation level statement for the given dialect,
+// lowercased. It is meant as a stable cache or log key
+func (s SetTransactionStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	sql, err := s.compile(dialect)
+	if err != nil {
+		return "", err
+	}
+	return lowerSQL(normalizeSQL(sql)), nil
+}
+
+// Build generates a statement out of SetTransactionStmt object
+// It panics if the level is unknown or the dialect does not support it
+func (s SetTransactionStmt) Build(dialect Dialect) *Stmt {
+	statement := Statement()
+	statement.Text(s.SQL(dialect))
+	return statement
+}
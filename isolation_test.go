@@ -0,0 +1,47 @@
+package qb
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetTransaction(t *testing.T) {
+	postgres := NewDialect("postgres")
+	mysql := NewDialect("mysql")
+
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE;", SetTransaction(Serializable).Build(postgres).SQL())
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE;", SetTransaction(Serializable).Build(mysql).SQL())
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL READ COMMITTED;", SetTransaction(ReadCommitted).Build(postgres).SQL())
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL READ COMMITTED;", SetTransaction(ReadCommitted).Build(mysql).SQL())
+
+	assert.Equal(t,
+		"SET SESSION CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL READ COMMITTED;",
+		SetTransaction(ReadCommitted).Session().Build(postgres).SQL())
+	assert.Equal(t,
+		"SET SESSION TRANSACTION ISOLATION LEVEL SERIALIZABLE;",
+		SetTransaction(Serializable).Session().Build(mysql).SQL())
+
+	assert.Panics(t, func() { SetTransaction(Serializable).Build(NewDialect("sqlite3")) })
+	assert.Panics(t, func() { SetTransaction("SNAPSHOT").Build(postgres) })
+
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE;", SetTransaction(Serializable).SQL(postgres))
+	assert.Panics(t, func() { SetTransaction(Serializable).SQL(NewDialect("sqlite3")) })
+	assert.Equal(t, "SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED;", fmt.Sprint(SetTransaction(ReadCommitted).Session()))
+	assert.Equal(t, "<invalid statement: Unknown isolation level 'SNAPSHOT'>", SetTransaction("SNAPSHOT").String())
+
+	sql, binds, err := SetTransaction(Serializable).ToSQL(mysql)
+	assert.Nil(t, err)
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE;", sql)
+	assert.Nil(t, binds)
+	_, _, err = SetTransaction(Serializable).ToSQL(NewDialect("sqlite3"))
+	assert.EqualError(t, err, "SET TRANSACTION ISOLATION LEVEL is not supported by sqlite")
+	_, _, err = SetTransaction("SNAPSHOT").ToSQL(postgres)
+	assert.EqualError(t, err, "Unknown isolation level 'SNAPSHOT'")
+
+	assert.Empty(t, SetTransaction(ReadCommitted).Validate(postgres))
+	assert.Len(t, SetTransaction(ReadCommitted).Validate(NewDialect("sqlite3")), 1)
+	sql, err = SetTransaction(ReadCommitted).CanonicalSQL(postgres)
+	assert.Nil(t, err)
+	assert.Equal(t, "set transaction isolation level read committed;", sql)
+}
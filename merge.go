@@ -0,0 +1,136 @@
+package qb
+
+// Merge generates a merge statement, that updates, deletes or inserts rows
+// of the target table depending on whether they match the rows of the source
+// Merge(users, Alias("s", staging)).
+// On(users.C("id").Eq(staging.C("id"))).
+// WhenMatched(MergeUpdate(map[string]interface{}{"email": staging.C("email")})).
+// WhenNotMatched(MergeInsert(map[string]interface{}{"id": staging.C("id"), "email": staging.C("email")}))
+// NOTE: mysql and sqlite do not support it, postgres needs version 15
+func Merge(target TableElem, source Selectable) MergeStmt {
+	return MergeStmt{
+		target: target,
+		source: source,
+	}
+}
+
+// MergeStmt is the base struct for building merge statements
+type MergeStmt struct {
+	target  TableElem
+	source  Selectable
+	on      Clause
+	actions []mergeWhen
+}
+
+// mergeWhen is a WHEN [NOT] MATCHED branch of a merge statement
+type mergeWhen struct {
+	matched bool
+	action  MergeAction
+}
+
+// On sets the condition joining the rows of the source to the ones of the
+// target. Several clauses are combined with AND
+func (s MergeStmt) On(clauses ...Clause) MergeStmt {
+	s.on = Where(clauses...).clause
+	return s
+}
+
+// WhenMatched appends the action to run on the target rows that match a
+// source row: MergeUpdate(), MergeDelete() or MergeDoNothing()
+// The branches are tried in the order they are added
+func (s MergeStmt) WhenMatched(action MergeAction) MergeStmt {
+	s.actions = append(append([]mergeWhen{}, s.actions...), mergeWhen{true, action})
+	return s
+}
+
+// WhenNotMatched appends the action to run on the source rows that match no
+// target row: MergeInsert() or MergeDoNothing()
+// The branches are tried in the order they are added
+func (s MergeStmt) WhenNotMatched(action MergeAction) MergeStmt {
+	s.actions = append(append([]mergeWhen{}, s.actions...), mergeWhen{false, action})
+	return s
+}
+
+// Accept implements Clause.Accept
+func (s MergeStmt) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitMerge(context, s)
+}
+
+// Build generates a statement out of MergeStmt object
+// It panics if the statement cannot be compiled
+func (s MergeStmt) Build(dialect Dialect) *Stmt {
+	return mustBuildStmt(dialect, s)
+}
+
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s MergeStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
+
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s MergeStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s MergeStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
+
+// String compiles the statement with the default dialect, for inspection
+func (s MergeStmt) String() string {
+	return stmtString(s)
+}
+
+// The kinds of merge actions
+const (
+	mergeUpdate    = "UPDATE"
+	mergeDelete    = "DELETE"
+	mergeInsert    = "INSERT"
+	mergeDoNothing = "DO NOTHING"
+)
+
+// MergeUpdate returns a merge action that updates the matched target row
+// with the given values. A value can be a Clause, typically a source column,
+// any other value is bound
+func MergeUpdate(values map[string]interface{}) MergeAction {
+	return MergeAction{kind: mergeUpdate, values: values}
+}
+
+// MergeDelete returns a merge action that deletes the matched target row
+func MergeDelete() MergeAction {
+	return MergeAction{kind: mergeDelete}
+}
+
+// MergeInsert returns a merge action that inserts a target row with the
+// given values. A value can be a Clause, typically a source column, any
+// other value is bound
+func MergeInsert(values map[string]interface{}) MergeAction {
+	return MergeAction{kind: mergeInsert, values: values}
+}
+
+// MergeDoNothing returns a merge action that leaves the row as is
+// NOTE: it is postgres specific
+func MergeDoNothing() MergeAction {
+	return MergeAction{kind: mergeDoNothing}
+}
+
+// MergeAction is the action of a WHEN [NOT] MATCHED branch of a merge
+// statement
+type MergeAction struct {
+	kind      string
+	values    map[string]interface{}
+	condition Clause
+}
+
+// Where sets an additional condition the rows must match for the action to
+// run: WHEN MATCHED AND <condition> THEN ...
+// Several clauses are combined with AND
+func (a MergeAction) Where(clauses ...Clause) MergeAction {
+	a.condition = Where(clauses...).clause
+	return a
+}
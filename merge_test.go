@@ -0,0 +1,58 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()), Column("active", Boolean()))
+	staging := Table("staging", Column("id", Int()), Column("email", Varchar()), Column("deleted", Boolean()))
+
+	merge := Merge(users, staging).
+		On(users.C("id").Eq(staging.C("id"))).
+		WhenMatched(MergeDelete().Where(staging.C("deleted").Eq(true))).
+		WhenMatched(MergeUpdate(map[string]interface{}{"email": staging.C("email"), "active": true})).
+		WhenNotMatched(MergeInsert(map[string]interface{}{"id": staging.C("id"), "email": staging.C("email"), "active": false}))
+
+	statement := merge.Build(NewDialect("postgres"))
+	assert.Equal(t, "MERGE INTO users\n"+
+		"USING staging ON users.id = staging.id\n"+
+		"WHEN MATCHED AND staging.deleted = $1 THEN DELETE\n"+
+		"WHEN MATCHED THEN UPDATE SET active = $2, email = staging.email\n"+
+		"WHEN NOT MATCHED THEN INSERT (active, email, id) VALUES ($3, staging.email, staging.id);", statement.SQL())
+	assert.Equal(t, []interface{}{true, true, false}, statement.Bindings())
+
+	// a values list source, its binds come first
+	source := Alias("s", Values([]string{"id", "email"}, []interface{}{1, "a@b.c"}))
+	statement = Merge(users, source).
+		On(users.C("id").Eq(source.C("id"))).
+		WhenMatched(MergeDoNothing()).
+		WhenNotMatched(MergeInsert(map[string]interface{}{"id": source.C("id"), "email": source.C("email")}).Where(source.C("id").Gt(0))).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "MERGE INTO users\n"+
+		"USING (VALUES ($1, $2)) AS s(id, email) ON users.id = s.id\n"+
+		"WHEN MATCHED THEN DO NOTHING\n"+
+		"WHEN NOT MATCHED AND s.id > $3 THEN INSERT (email, id) VALUES (s.email, s.id);", statement.SQL())
+	assert.Equal(t, []interface{}{1, "a@b.c", 0}, statement.Bindings())
+
+	_, _, err := merge.ToSQL(NewDialect("mysql"))
+	assert.EqualError(t, err, "MERGE is not supported by mysql")
+	_, _, err = merge.ToSQL(NewDialect("sqlite3"))
+	assert.EqualError(t, err, "MERGE is not supported by sqlite")
+
+	_, _, err = Merge(users, staging).WhenMatched(MergeDelete()).ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "Merge has no ON condition")
+	_, _, err = Merge(users, staging).On(users.C("id").Eq(staging.C("id"))).ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "Merge has no WHEN MATCHED or WHEN NOT MATCHED action")
+	_, _, err = Merge(users, staging).
+		On(users.C("id").Eq(staging.C("id"))).
+		WhenNotMatched(MergeDelete()).
+		ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "DELETE is not a valid WHEN NOT MATCHED action")
+	_, _, err = Merge(users, staging).
+		On(users.C("id").Eq(staging.C("id"))).
+		WhenMatched(MergeInsert(map[string]interface{}{"id": 1})).
+		ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "INSERT is not a valid WHEN MATCHED action")
+}
@@ -0,0 +1,57 @@
+package qb
+
+// Neg generates a negation of a numeric expression: -amount
+func Neg(clause Clause) UnaryClause {
+	return Unary("-", clause)
+}
+
+// BitNot generates a bitwise NOT of an integer expression: ~flags
+func BitNot(clause Clause) UnaryClause {
+	return Unary("~", clause)
+}
+
+// bitXor is the operator of BitXor, which the dialects compile to their own
+const bitXor = "#"
+
+// BitAnd generates a bitwise AND expression: flags & 4
+func BitAnd(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "&", GetClauseFrom(right))
+}
+
+// BitOr generates a bitwise OR expression: flags | 4
+func BitOr(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "|", GetClauseFrom(right))
+}
+
+// BitXor generates a bitwise XOR expression: flags # 4 on postgres,
+// flags ^ 4 on mysql
+// NOTE: sqlite does not support it
+func BitXor(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, bitXor, GetClauseFrom(right))
+}
+
+// ShiftLeft generates a bitwise left shift expression: flags << 2
+func ShiftLeft(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "<<", GetClauseFrom(right))
+}
+
+// ShiftRight generates a bitwise right shift expression: flags >> 2
+func ShiftRight(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, ">>", GetClauseFrom(right))
+}
+
+// Unary generates an expression of a prefix operator
+func Unary(op string, clause Clause) UnaryClause {
+	return UnaryClause{Op: op, Clause: clause}
+}
+
+// UnaryClause is a prefix operator applied to an expression
+type UnaryClause struct {
+	Op     string
+	Clause Clause
+}
+
+// Accept calls the compiler VisitUnary function
+func (c UnaryClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitUnary(context, c)
+}
@@ -0,0 +1,45 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnary(t *testing.T) {
+	accounts := Table("accounts", Column("id", Int()), Column("amount", Int()), Column("flags", Int()))
+
+	assert.Equal(t, "-accounts.amount", asDefSQL(Neg(accounts.C("amount"))))
+	assert.Equal(t, "-(-accounts.amount)", asDefSQL(Neg(Neg(accounts.C("amount")))))
+	assert.Equal(t, "-(accounts.amount - accounts.id)", asDefSQL(Neg(BinaryExpression(accounts.C("amount"), "-", accounts.C("id")))))
+
+	statement := Select(accounts.C("id"), As(Neg(accounts.C("amount")), "debit")).
+		From(accounts).
+		Where(Eq(BitNot(accounts.C("flags")), 0)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id, -amount AS debit\nFROM accounts\nWHERE ~flags = $1;", statement.SQL())
+	assert.Equal(t, []interface{}{0}, statement.Bindings())
+
+	sql, binds := asSQLBinds(BitNot(Bind(6)), NewDialect("mysql"))
+	assert.Equal(t, "~?", sql)
+	assert.Equal(t, []interface{}{6}, binds)
+}
+
+func TestBitwise(t *testing.T) {
+	accounts := Table("accounts", Column("id", Int()), Column("flags", Int()))
+	flags := accounts.C("flags")
+
+	statement := Select(accounts.C("id")).
+		From(accounts).
+		Where(Eq(BitAnd(flags, 4), 4), NotEq(BitOr(flags, 1), 0)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM accounts\nWHERE (flags & $1 = $2 AND flags | $3 != $4);", statement.SQL())
+	assert.Equal(t, []interface{}{4, 4, 1, 0}, statement.Bindings())
+
+	assert.Equal(t, "accounts.flags << ?", asDefSQL(ShiftLeft(flags, 2)))
+	assert.Equal(t, "accounts.flags >> ?", asDefSQL(ShiftRight(flags, 2)))
+
+	assert.Equal(t, "accounts.flags # $1", asSQL(BitXor(flags, 8), NewDialect("postgres")))
+	assert.Equal(t, "accounts.flags ^ ?", asSQL(BitXor(flags, 8), NewDialect("mysql")))
+	_, err := BuildStmt(NewCompilerContext(NewDialect("sqlite3")), Select(BitXor(flags, 8)).From(accounts))
+	assert.EqualError(t, err, "Bitwise XOR is not supported by this compiler")
+}
@@ -0,0 +1,46 @@
+package qb
+
+import "strings"
+
+// The keywords that cannot be used as unquoted identifiers, by driver
+var (
+	postgresReservedWords = wordSet(commonReservedWords +
+		" analyse analyze array asymmetric authorization binary collation concurrently" +
+		" current_catalog current_role current_schema do freeze ilike initially lateral" +
+		" localtime localtimestamp offset only overlaps placing returning session_user" +
+		" similar some symmetric tablesample user variadic verbose window")
+	mysqlReservedWords = wordSet(commonReservedWords +
+		" alter before call change condition database databases delete describe div drop" +
+		" dual explain force fulltext groups ignore index insert interval key keys kill" +
+		" lines load lock match mod optimize option partition procedure range rank read" +
+		" regexp rename replace require restrict return revoke rlike row rows schema set" +
+		" show trigger unlock update usage use values while window write xor")
+	sqliteReservedWords = wordSet(commonReservedWords +
+		" alter autoincrement commit delete drop escape exists if index insert notnull" +
+		" set transaction update values")
+	// the default dialect uses the words reserved by all the drivers
+	defaultReservedWords = wordSet(commonReservedWords)
+)
+
+// commonReservedWords are the keywords reserved by all the drivers
+const commonReservedWords = "all and as asc between both by case cast check collate" +
+	" column constraint create cross current_date current_time current_timestamp" +
+	" current_user default deferrable desc distinct else end except false fetch for" +
+	" foreign from full grant group having in inner intersect into is isnull join" +
+	" leading left like limit natural not null on or order outer primary references" +
+	" right select table then to trailing true union unique using when where with"
+
+// wordSet returns the set of the space separated words
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// isReservedWord returns true if the identifier is one of the words,
+// whatever its case
+func isReservedWord(words map[string]bool, identifier string) bool {
+	return words[strings.ToLower(identifier)]
+}
@@ -0,0 +1,114 @@
+package qb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/serenize/snaker"
+)
+
+// ScanOne scans a row into dest, which must be a pointer to a struct.
+// The statement columns are mapped to the struct fields by their 'db' tag,
+// or by their snake-cased name if they have none.
+// Nullable columns (for example from a left join) should be mapped to
+// pointer fields.
+func (s *Stmt) ScanOne(row *sql.Row, dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return errors.New("ScanOne: dest must be a pointer to a struct")
+	}
+	targets, err := s.scanTargets(value.Elem())
+	if err != nil {
+		return err
+	}
+	return row.Scan(targets...)
+}
+
+// ScanAll scans all the rows into dest, which must be a pointer to a slice
+// of structs or of pointers to structs. See ScanOne for the mapping rules.
+// The rows are closed when done.
+func (s *Stmt) ScanAll(rows *sql.Rows, dest interface{}) error {
+	defer rows.Close()
+
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return errors.New("ScanAll: dest must be a pointer to a slice")
+	}
+	slice := value.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("ScanAll: dest must be a pointer to a slice of structs")
+	}
+
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		targets, err := s.scanTargets(elem.Elem())
+		if err != nil {
+			return err
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return err
+		}
+		if isPtr {
+			slice = reflect.Append(slice, elem)
+		} else {
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+	value.Elem().Set(slice)
+	return rows.Err()
+}
+
+// structField is an exported field of a struct and the column it maps
+type structField struct {
+	index  int
+	column string
+}
+
+// structFields returns the exported fields of a struct type, in declaration
+// order, with the column each of them maps: its 'db' tag, or its
+// snake-cased name if it has none
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported field
+			continue
+		}
+		name := field.Tag.Get("db")
+		if name == "" {
+			name = snaker.CamelToSnake(field.Name)
+		}
+		fields = append(fields, structField{i, name})
+	}
+	return fields
+}
+
+// scanTargets returns the addresses of the struct fields matching the
+// statement columns, in the same order
+func (s *Stmt) scanTargets(value reflect.Value) ([]interface{}, error) {
+	fields := map[string]int{}
+	for _, field := range structFields(value.Type()) {
+		fields[field.column] = field.index
+	}
+
+	var targets []interface{}
+	for _, column := range s.columns {
+		if column == "" {
+			return nil, errors.New("Cannot scan an unnamed column, please give it an alias")
+		}
+		i, ok := fields[column]
+		if !ok {
+			return nil, fmt.Errorf("No field of %s maps the '%s' column", value.Type(), column)
+		}
+		targets = append(targets, value.Field(i).Addr().Interface())
+	}
+	return targets, nil
+}
@@ -0,0 +1,94 @@
+package qb
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	type UserSession struct {
+		ID    int64   `db:"id"`
+		Email string  `db:"email"`
+		Token *string `db:"token"`
+	}
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	dialect := NewDialect("sqlite3")
+
+	users := Table(
+		"users",
+		Column("id", BigInt()),
+		Column("email", Varchar()).NotNull(),
+		PrimaryKey("id"),
+	)
+	sessions := Table(
+		"sessions",
+		Column("user_id", BigInt()),
+		Column("token", Varchar()).NotNull(),
+		ForeignKey("user_id").References("users", "id"),
+	)
+
+	exec := func(builder Builder) {
+		statement := builder.Build(dialect)
+		_, err := db.Exec(statement.SQL(), statement.Bindings()...)
+		assert.Nil(t, err)
+	}
+
+	for _, table := range []TableElem{users, sessions} {
+		_, err = db.Exec(table.Create(dialect))
+		assert.Nil(t, err)
+	}
+	exec(Insert(users).Values(map[string]interface{}{"id": 1, "email": "al@pacino.com"}))
+	exec(Insert(users).Values(map[string]interface{}{"id": 2, "email": "robert@de.niro"}))
+	exec(Insert(sessions).Values(map[string]interface{}{"user_id": 1, "token": "abc"}))
+
+	sel := Select(users.C("id"), users.C("email"), As(sessions.C("token"), "token")).
+		From(users).
+		LeftJoin(sessions, users.C("id"), sessions.C("user_id")).
+		OrderBy(users.C("id"))
+	statement := sel.Build(dialect)
+
+	var one UserSession
+	err = statement.ScanOne(db.QueryRow(statement.SQL(), statement.Bindings()...), &one)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), one.ID)
+	assert.Equal(t, "al@pacino.com", one.Email)
+	if assert.NotNil(t, one.Token) {
+		assert.Equal(t, "abc", *one.Token)
+	}
+
+	var all []UserSession
+	rows, err := db.Query(statement.SQL(), statement.Bindings()...)
+	assert.Nil(t, err)
+	assert.Nil(t, statement.ScanAll(rows, &all))
+	assert.Equal(t, 2, len(all))
+	assert.Equal(t, one, all[0])
+	assert.Equal(t, int64(2), all[1].ID)
+	assert.Equal(t, "robert@de.niro", all[1].Email)
+	assert.Nil(t, all[1].Token)
+
+	var allPtr []*UserSession
+	rows, err = db.Query(statement.SQL(), statement.Bindings()...)
+	assert.Nil(t, err)
+	assert.Nil(t, statement.ScanAll(rows, &allPtr))
+	assert.Equal(t, 2, len(allPtr))
+	assert.Equal(t, one, *allPtr[0])
+
+	assert.NotNil(t, statement.ScanOne(db.QueryRow(statement.SQL()), one))
+
+	type Partial struct {
+		ID int64 `db:"id"`
+	}
+	var partial Partial
+	assert.NotNil(t, statement.ScanOne(db.QueryRow(statement.SQL()), &partial))
+
+	unnamed := Select(Count(users.C("id"))).From(users).Build(dialect)
+	var count struct{ Count int }
+	assert.NotNil(t, unnamed.ScanOne(db.QueryRow(unnamed.SQL()), &count))
+}
@@ -13,24 +13,67 @@ type Selectable interface {
 }
 
 // Select generates a select statement and returns it
+// A select statement without any clause cannot be built, use SQLText("*")
+// to select all the columns
 func Select(clauses ...Clause) SelectStmt {
 	return SelectStmt{
 		sel:     clauses,
-		groupBy: []ColumnElem{},
+		groupBy: []Clause{},
 		having:  []HavingClause{},
 	}
 }
 
 // SelectStmt is the base struct for building select statements
 type SelectStmt struct {
+	with        WithClause
+	distinct    bool
+	distinctOn  []ColumnElem
 	sel         []Clause
+	into        *IntoClause
 	from        Selectable
-	groupBy     []ColumnElem
+	groupBy     []Clause
+	groupByAll  bool
 	orderBy     *OrderByClause
 	having      []HavingClause
 	WhereClause *WhereClause
 	offset      *int
 	count       *int
+	lock        *LockClause
+	raw         []RawClause
+}
+
+// RawPosition is a position of a select statement where raw SQL can be
+// appended
+type RawPosition int
+
+// The positions of the raw SQL of a select statement
+const (
+	// RawAfterFrom is after the FROM clause and its joins
+	RawAfterFrom RawPosition = iota
+	// RawAfterWhere is after the WHERE clause
+	RawAfterWhere
+	// RawTrailing is at the end of the statement
+	RawTrailing
+)
+
+// RawClause is a raw SQL clause appended at a position of a select statement
+type RawClause struct {
+	Position RawPosition
+	Clause   TextClause
+}
+
+// AppendRaw appends a raw SQL clause, with optional binds, at a position of
+// the select statement. It is an escape hatch for the SQL features that are
+// not supported, like a trailing FOR SYSTEM_TIME AS OF ?
+func (s SelectStmt) AppendRaw(position RawPosition, sql string, binds ...interface{}) SelectStmt {
+	s.raw = append(append([]RawClause{}, s.raw...), RawClause{position, SQLTextBinds(sql, binds...)})
+	return s
+}
+
+// With appends common table expressions to the select statement
+func (s SelectStmt) With(ctes ...CTEClause) SelectStmt {
+	s.with.CTEs = append(append([]CTEClause{}, s.with.CTEs...), ctes...)
+	return s
 }
 
 // Select sets the selected columns
@@ -39,6 +82,28 @@ func (s SelectStmt) Select(clauses ...Clause) SelectStmt {
 	return s
 }
 
+// Distinct makes the statement a SELECT DISTINCT
+func (s SelectStmt) Distinct() SelectStmt {
+	s.distinct = true
+	return s
+}
+
+// DistinctOn makes the statement a SELECT DISTINCT ON (<columns>), which
+// keeps the first row of each set of rows having the same columns values
+// NOTE: DISTINCT ON is postgres specific
+func (s SelectStmt) DistinctOn(columns ...ColumnElem) SelectStmt {
+	s.distinctOn = columns
+	return s
+}
+
+// Into makes the statement create a new table from its result:
+// SELECT ... INTO table FROM ...
+// NOTE: mysql and sqlite have no SELECT INTO, use CreateTableAs instead
+func (s SelectStmt) Into(table TableElem) SelectStmt {
+	s.into = &IntoClause{table}
+	return s
+}
+
 // From sets the from selectable of select statement
 func (s SelectStmt) From(selectable Selectable) SelectStmt {
 	s.from = selectable
@@ -52,14 +117,21 @@ func (s SelectStmt) Where(clauses ...Clause) SelectStmt {
 	return s
 }
 
+// Join appends a join clause of the given type to the select statement, for
+// the join types that have no dedicated function, like mysql STRAIGHT_JOIN
+func (s SelectStmt) Join(joinType string, right Selectable, onClause ...Clause) SelectStmt {
+	return s.From(Join(joinType, s.from, right, onClause...))
+}
+
 // InnerJoin appends an inner join clause to the select statement
 func (s SelectStmt) InnerJoin(right Selectable, onClause ...Clause) SelectStmt {
 	return s.From(Join("INNER JOIN", s.from, right, onClause...))
 }
 
-// CrossJoin appends an cross join clause to the select statement
+// CrossJoin appends an cross join clause to the select statement, which has
+// no ON condition
 func (s SelectStmt) CrossJoin(right Selectable) SelectStmt {
-	return s.From(Join("CROSS JOIN", s.from, right, nil))
+	return s.From(JoinClause{JoinType: "CROSS JOIN", Left: s.from, Right: right})
 }
 
 // LeftJoin appends an left outer join clause to the select statement
@@ -72,10 +144,19 @@ func (s SelectStmt) RightJoin(right Selectable, onClause ...Clause) SelectStmt {
 	return s.From(Join("RIGHT OUTER JOIN", s.from, right, onClause...))
 }
 
+// FullOuterJoin appends a full outer join clause to select statement
+// NOTE: mysql does not support it
+func (s SelectStmt) FullOuterJoin(right Selectable, onClause ...Clause) SelectStmt {
+	return s.From(Join("FULL OUTER JOIN", s.from, right, onClause...))
+}
+
 // OrderBy generates an OrderByClause and sets select statement's orderbyclause
 // OrderBy(usersTable.C("id")).Asc()
 // OrderBy(usersTable.C("email")).Desc()
-func (s SelectStmt) OrderBy(columns ...ColumnElem) SelectStmt {
+// The columns can be any clause: columns, alias references, aggregates or
+// ordinals. Use Asc() & Desc() to give each of them its own direction:
+// OrderBy(Desc(total.Ref()), Asc(usersTable.C("name")).NullsLast())
+func (s SelectStmt) OrderBy(columns ...Clause) SelectStmt {
 	s.orderBy = &OrderByClause{columns, "ASC"}
 	return s
 }
@@ -83,51 +164,200 @@ func (s SelectStmt) OrderBy(columns ...ColumnElem) SelectStmt {
 // Asc sets the t type of current order by clause
 // NOTE: Please use it after calling OrderBy()
 func (s SelectStmt) Asc() SelectStmt {
-	s.orderBy.t = "ASC"
+	orderBy := *s.orderBy
+	orderBy.t = "ASC"
+	s.orderBy = &orderBy
 	return s
 }
 
 // Desc sets the t type of current order by clause
 // NOTE: Please use it after calling OrderBy()
 func (s SelectStmt) Desc() SelectStmt {
-	s.orderBy.t = "DESC"
+	orderBy := *s.orderBy
+	orderBy.t = "DESC"
+	s.orderBy = &orderBy
 	return s
 }
 
 // GroupBy appends columns to group by clause of the select statement
-func (s SelectStmt) GroupBy(cols ...ColumnElem) SelectStmt {
-	s.groupBy = append(s.groupBy, cols...)
+// Besides the columns, it accepts expressions and alias references:
+// GroupBy(SQLText("date_trunc('day', created_at)"), AliasRef("total"))
+func (s SelectStmt) GroupBy(cols ...Clause) SelectStmt {
+	s.groupBy = append(append([]Clause{}, s.groupBy...), cols...)
+	return s
+}
+
+// GroupByAll groups by all the selected expressions that are not
+// aggregated: GROUP BY ALL. The dialects that do not support it, postgres,
+// mysql and sqlite, get these expressions as an explicit GROUP BY list
+// It cannot be combined with GroupBy()
+func (s SelectStmt) GroupByAll() SelectStmt {
+	s.groupByAll = true
 	return s
 }
 
-// Having appends a having clause to select statement
-func (s SelectStmt) Having(aggregate AggregateClause, op string, value interface{}) SelectStmt {
-	s.having = append(s.having, HavingClause{aggregate, op, value})
+// Having appends a having condition to select statement
+// The conditions of several calls are combined with AND
+// The clause is usually an aggregate, but can also be a reference to a
+// select list alias (see AliasRef) on the dialects that allow it
+func (s SelectStmt) Having(clause Clause, op string, value interface{}) SelectStmt {
+	s.having = append(append([]HavingClause{}, s.having...), HavingClause{clause, op, value})
 	return s
 }
 
 // Limit sets the offset & count values of the select statement
+// Negative values are reported as errors when the statement is built
 func (s SelectStmt) Limit(offset int, count int) SelectStmt {
 	s.offset = &offset
 	s.count = &count
 	return s
 }
 
+// LimitCount sets the count value of the select statement only, to limit
+// the number of rows without an offset
+func (s SelectStmt) LimitCount(count int) SelectStmt {
+	s.count = &count
+	return s
+}
+
+// Offset sets the offset value of the select statement only, to skip rows
+// without limiting their number
+func (s SelectStmt) Offset(offset int) SelectStmt {
+	s.offset = &offset
+	return s
+}
+
+// ForUpdate locks the selected rows: FOR UPDATE
+// If tables are given, only the rows of these tables are locked, which is
+// useful in a join: FOR UPDATE OF users
+// NOTE: sqlite does not support it
+func (s SelectStmt) ForUpdate(of ...TableElem) SelectStmt {
+	s.lock = &LockClause{Strength: "UPDATE", Of: of}
+	return s
+}
+
+// OnLocked sets what the lock does with the rows that are already locked
+// by another transaction: wait for them (LockWait, the default), fail
+// (LockNoWait) or skip them (LockSkipLocked)
+// It replaces a previous Wait() timeout
+// NOTE: Please use it after calling ForUpdate()
+func (s SelectStmt) OnLocked(strategy LockStrategy) SelectStmt {
+	lock := *s.lock
+	lock.Strategy = strategy
+	lock.Timeout = nil
+	s.lock = &lock
+	return s
+}
+
+// Wait makes the lock wait at most the given number of seconds for the
+// rows that are already locked, then fail: FOR UPDATE WAIT <seconds>
+// It replaces a previous OnLocked() strategy
+// NOTE: Please use it after calling ForUpdate(). postgres, mysql and sqlite
+// do not support it, their lock timeout is a session setting
+func (s SelectStmt) Wait(seconds int) SelectStmt {
+	lock := *s.lock
+	lock.Strategy = LockWait
+	lock.Timeout = &seconds
+	s.lock = &lock
+	return s
+}
+
+// Count returns a copy of the statement that selects COUNT(*) instead of
+// its select list. The WITH, FROM (and joins), WHERE, GROUP BY and HAVING
+// parts are kept, the ORDER BY, LIMIT and FOR UPDATE ones are dropped.
+// On an ungrouped statement it gives the total number of matching rows, on
+// a grouped one it gives the number of rows of each group.
+// A DISTINCT (ON) statement is counted as a subquery, so only the distinct
+// rows are counted: SELECT COUNT(*) FROM (SELECT DISTINCT ...) AS t
+func (s SelectStmt) Count() SelectStmt {
+	s.orderBy = nil
+	s.offset = nil
+	s.count = nil
+	s.lock = nil
+	if s.distinct || len(s.distinctOn) > 0 {
+		with := s.with
+		s.with = WithClause{}
+		count := Select(CountStar()).From(Alias("t", Subquery(s)))
+		count.with = with
+		return count
+	}
+	s.sel = []Clause{CountStar()}
+	return s
+}
+
+// ColumnNames returns the names of the selected columns, in order.
+// The name of an aliased clause is its alias. The expressions that have
+// no name, like a non-aliased aggregate, get an empty name.
+func (s SelectStmt) ColumnNames() []string {
+	return columnNames(s.sel)
+}
+
+// columnNames returns the names of a list of selected clauses
+func columnNames(clauses []Clause) []string {
+	names := []string{}
+	for _, clause := range clauses {
+		var name string
+		switch c := clause.(type) {
+		case ColumnElem:
+			name = c.Name
+		case AsClause:
+			name = c.Name
+		case AliasRefClause:
+			name = c.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 // Accept calls the compiler VisitSelect method
 func (s SelectStmt) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitSelect(context, s)
 }
 
 // Build compiles the select statement and returns the Stmt
+// It panics if the statement cannot be compiled
 func (s SelectStmt) Build(dialect Dialect) *Stmt {
-	context := NewCompilerContext(dialect)
-	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
-	statement.AddBinding(context.Binds...)
+	return mustBuildStmt(dialect, s)
+}
 
+// BuildNamed generates a statement out of SelectStmt object, with the values
+// of its BindName() parameters
+// It panics if the statement cannot be compiled, or if a parameter is missing
+func (s SelectStmt) BuildNamed(dialect Dialect, params map[string]interface{}) *Stmt {
+	context := NewCompilerContext(dialect)
+	context.SetParams(params)
+	statement, err := BuildStmt(context, s)
+	if err != nil {
+		panic(err)
+	}
 	return statement
 }
 
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s SelectStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
+
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s SelectStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s SelectStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
+
+// String compiles the statement with the default dialect, for inspection
+func (s SelectStmt) String() string {
+	return stmtString(s)
+}
+
 type joinOnClauseCandidate struct {
 	source TableElem
 	fkey   ForeignKeyConstraint
@@ -279,7 +509,7 @@ func (c JoinClause) DefaultName() string {
 // OrderByClause is the base struct for generating order by clauses when using select
 // It satisfies SQLClause interface
 type OrderByClause struct {
-	columns []ColumnElem
+	columns []Clause
 	t       string
 }
 
@@ -288,12 +518,118 @@ func (c OrderByClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitOrderBy(context, c)
 }
 
+// Asc returns an ascending order by expression
+func Asc(clause Clause) OrderingClause {
+	return OrderingClause{Clause: clause, Direction: "ASC"}
+}
+
+// Desc returns a descending order by expression
+func Desc(clause Clause) OrderingClause {
+	return OrderingClause{Clause: clause, Direction: "DESC"}
+}
+
+// OrderingClause is an order by expression with its own direction and
+// nulls ordering
+type OrderingClause struct {
+	Clause    Clause
+	Direction string
+	Nulls     string
+	Operator  string
+}
+
+// Using sorts with the given operator instead of the direction:
+// ORDER BY x USING >
+// NOTE: only postgres supports it
+func (c OrderingClause) Using(op string) OrderingClause {
+	c.Direction = ""
+	c.Operator = op
+	return c
+}
+
+// NullsFirst sorts the null values before the others
+func (c OrderingClause) NullsFirst() OrderingClause {
+	c.Nulls = "FIRST"
+	return c
+}
+
+// NullsLast sorts the null values after the others
+func (c OrderingClause) NullsLast() OrderingClause {
+	c.Nulls = "LAST"
+	return c
+}
+
+// Accept calls the compiler VisitOrdering function
+func (c OrderingClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitOrdering(context, c)
+}
+
+// LimitClause is the LIMIT and OFFSET clause of a select statement, either
+// value being optional
+type LimitClause struct {
+	Offset *int
+	Count  *int
+}
+
+// Accept calls the compiler VisitLimit function
+func (c LimitClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitLimit(context, c)
+}
+
+// DistinctOnClause is the DISTINCT ON (<columns>) clause of a select
+// statement
+type DistinctOnClause struct {
+	Columns []ColumnElem
+}
+
+// Accept calls the compiler VisitDistinctOn function
+func (c DistinctOnClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitDistinctOn(context, c)
+}
+
+// LockStrategy is the behaviour of a lock on the rows that are already
+// locked
+type LockStrategy int
+
+const (
+	// LockWait waits for the locked rows to be released
+	LockWait LockStrategy = iota
+	// LockNoWait reports an error if a row is locked: NOWAIT
+	LockNoWait
+	// LockSkipLocked skips the locked rows: SKIP LOCKED
+	LockSkipLocked
+)
+
+// LockClause is the FOR UPDATE [OF <tables>] [NOWAIT | SKIP LOCKED | WAIT <n>]
+// clause
+// of a select statement
+type LockClause struct {
+	Strength string
+	Of       []TableElem
+	Strategy LockStrategy
+	Timeout  *int
+}
+
+// Accept calls the compiler VisitLock function
+func (c LockClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitLock(context, c)
+}
+
+// IntoClause is the INTO clause of a SELECT INTO statement
+type IntoClause struct {
+	Table TableElem
+}
+
+// Accept calls the compiler VisitInto function
+func (c IntoClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitInto(context, c)
+}
+
 // HavingClause is the base struct for generating having clauses when using select
 // It satisfies SQLClause interface
 type HavingClause struct {
-	aggregate AggregateClause
-	op        string
-	value     interface{}
+	clause Clause
+	op     string
+	value  interface{}
 }
 
 // Accept calls the compiler VisitHaving function
@@ -354,3 +690,93 @@ func (c AliasClause) ColumnList() []ColumnElem {
 func (c AliasClause) DefaultName() string {
 	return c.Name
 }
+
+// As returns a new AsClause
+func As(clause Clause, name string) AsClause {
+	return AsClause{
+		Name:   name,
+		Clause: clause,
+	}
+}
+
+// AsClause is a '<clause> AS <name>' sql clause. Unlike AliasClause, it
+// can name any expression, typically in a select list
+type AsClause struct {
+	Name   string
+	Clause Clause
+}
+
+// Accept calls the compiler VisitAs function
+func (c AsClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitAs(context, c)
+}
+
+// Ref returns a reference to the alias name
+func (c AsClause) Ref() AliasRefClause {
+	return AliasRef(c.Name)
+}
+
+// AliasRef returns a new AliasRefClause
+func AliasRef(name string) AliasRefClause {
+	return AliasRefClause{Name: name}
+}
+
+// AliasRefClause is a reference to an alias defined in the select list
+type AliasRefClause struct {
+	Name string
+}
+
+// Accept calls the compiler VisitAliasRef function
+func (c AliasRefClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitAliasRef(context, c)
+}
+
+// TableSample returns a new TableSampleClause
+func TableSample(selectable Selectable, method string, percentage float64) TableSampleClause {
+	return TableSampleClause{
+		Selectable: selectable,
+		Method:     method,
+		Percentage: percentage,
+	}
+}
+
+// TableSampleClause is a '<selectable> TABLESAMPLE <method> (<percentage>)
+// [REPEATABLE (<seed>)]' sql clause, to be used in a FROM clause
+type TableSampleClause struct {
+	Selectable Selectable
+	Method     string
+	Percentage float64
+	Seed       *int64
+}
+
+// Repeatable returns a copy of the sample that selects the same rows each
+// time it is run with the same seed, as long as the table is not changed
+func (c TableSampleClause) Repeatable(seed int64) TableSampleClause {
+	c.Seed = &seed
+	return c
+}
+
+// Accept calls the compiler VisitTableSample function
+func (c TableSampleClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitTableSample(context, c)
+}
+
+// All returns the sampled selectable columns
+func (c TableSampleClause) All() []Clause {
+	return c.Selectable.All()
+}
+
+// ColumnList returns the sampled selectable columns
+func (c TableSampleClause) ColumnList() []ColumnElem {
+	return c.Selectable.ColumnList()
+}
+
+// C returns the sampled selectable column with the given name
+func (c TableSampleClause) C(name string) ColumnElem {
+	return c.Selectable.C(name)
+}
+
+// DefaultName returns the sampled selectable default name
+func (c TableSampleClause) DefaultName() string {
+	return c.Selectable.DefaultName()
+}
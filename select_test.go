@@ -139,6 +139,300 @@ func (suite *SelectTestSuite) TestOrderByLimit() {
 	assert.Equal(suite.T(), []interface{}{5}, statement.Bindings())
 }
 
+func (suite *SelectTestSuite) TestEmptySelect() {
+	_, err := BuildStmt(NewCompilerContext(suite.postgres), Select().From(suite.users))
+	assert.EqualError(suite.T(), err, "Select has no columns")
+	assert.Panics(suite.T(), func() { Select().From(suite.users).Build(suite.sqlite) })
+
+	statement := Select(SQLText("*")).From(suite.users).Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT *\nFROM users;", statement.SQL())
+}
+
+func (suite *SelectTestSuite) TestNegativeLimit() {
+	sel := Select(suite.sessions.C("id")).From(suite.sessions)
+
+	_, err := BuildStmt(NewCompilerContext(suite.sqlite), sel.Limit(-5, 20))
+	assert.EqualError(suite.T(), err, "Invalid negative offset: -5")
+
+	_, err = BuildStmt(NewCompilerContext(suite.sqlite), sel.Limit(0, -20))
+	assert.EqualError(suite.T(), err, "Invalid negative limit: -20")
+
+	context := NewCompilerContext(suite.sqlite)
+	_, err = BuildStmt(context, sel.Limit(-1, -1))
+	assert.NotNil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(context.Errors))
+
+	assert.Panics(suite.T(), func() { sel.Limit(0, -20).Build(suite.sqlite) })
+	assert.NotPanics(suite.T(), func() { sel.Limit(0, 0).Build(suite.sqlite) })
+}
+
+func (suite *SelectTestSuite) TestDistinct() {
+	sel := Select(suite.sessions.C("user_id")).From(suite.sessions).Distinct()
+	assert.Equal(suite.T(), "SELECT DISTINCT user_id\nFROM sessions;", sel.Build(suite.sqlite).SQL())
+	assert.Equal(suite.T(), "SELECT `user_id`\nFROM `sessions`;", Select(suite.sessions.C("user_id")).From(suite.sessions).Build(suite.mysql).SQL())
+}
+
+func (suite *SelectTestSuite) TestDistinctOrderByStrict() {
+	strictBuild := func(sel SelectStmt) (*Stmt, error) {
+		context := NewCompilerContext(suite.postgres)
+		context.Strict = true
+		return BuildStmt(context, sel)
+	}
+
+	valid := Select(suite.sessions.C("user_id")).
+		From(suite.sessions).
+		Distinct().
+		OrderBy(suite.sessions.C("user_id"))
+	statement, err := strictBuild(valid)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "SELECT DISTINCT \"user_id\"\nFROM \"sessions\"\nORDER BY \"user_id\" ASC;", statement.SQL())
+
+	validAlias := Select(As(suite.sessions.C("user_id"), "uid")).
+		From(suite.sessions).
+		Distinct().
+		OrderBy(suite.sessions.C("user_id"))
+	_, err = strictBuild(validAlias)
+	assert.Nil(suite.T(), err)
+
+	invalid := Select(suite.sessions.C("user_id")).
+		From(suite.sessions).
+		Distinct().
+		OrderBy(suite.sessions.C("id"))
+	_, err = strictBuild(invalid)
+	assert.EqualError(suite.T(), err, "ORDER BY expression \"id\" must appear in the select list of a SELECT DISTINCT")
+
+	// not strict: left to the database
+	_, err = BuildStmt(NewCompilerContext(suite.postgres), invalid)
+	assert.Nil(suite.T(), err)
+
+	// not distinct
+	_, err = strictBuild(Select(suite.sessions.C("user_id")).
+		From(suite.sessions).
+		OrderBy(suite.sessions.C("id")))
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *SelectTestSuite) TestSelectJoinedColumn() {
+	orders := Table(
+		"orders",
+		Column("id", Int()).PrimaryKey(),
+		Column("user_id", Int()),
+	)
+	// a column added loosely, without its table name
+	orders.Columns["total"] = Column("total", Int())
+
+	sel := Select(suite.users.C("id"), orders.C("total")).
+		From(suite.users).
+		InnerJoin(orders, suite.users.C("id"), orders.C("user_id"))
+
+	assert.Equal(suite.T(), "orders", orders.C("total").Table)
+	assert.Equal(suite.T(), "SELECT users.id, orders.total\nFROM users\nINNER JOIN orders ON users.id = orders.user_id;", sel.Build(suite.sqlite).SQL())
+	assert.Equal(suite.T(), "SELECT \"users\".\"id\", \"orders\".\"total\"\nFROM \"users\"\nINNER JOIN \"orders\" ON \"users\".\"id\" = \"orders\".\"user_id\";", sel.Build(suite.postgres).SQL())
+}
+
+func (suite *SelectTestSuite) TestChainedJoins() {
+	addresses := Table(
+		"addresses",
+		Column("id", Int()).PrimaryKey(),
+		Column("user_id", Int()),
+		Column("city", Varchar()),
+	)
+
+	sel := Select(suite.sessions.C("auth_token"), suite.users.C("email"), addresses.C("city")).
+		From(suite.sessions).
+		InnerJoin(suite.users, suite.sessions.C("user_id"), suite.users.C("id")).
+		LeftJoin(addresses, suite.users.C("id"), addresses.C("user_id"))
+
+	assert.Equal(suite.T(), "SELECT sessions.auth_token, users.email, addresses.city\nFROM sessions\nINNER JOIN users ON sessions.user_id = users.id\nLEFT OUTER JOIN addresses ON users.id = addresses.user_id;", sel.Build(suite.sqlite).SQL())
+	assert.Equal(suite.T(), "SELECT \"sessions\".\"auth_token\", \"users\".\"email\", \"addresses\".\"city\"\nFROM \"sessions\"\nINNER JOIN \"users\" ON \"sessions\".\"user_id\" = \"users\".\"id\"\nLEFT OUTER JOIN \"addresses\" ON \"users\".\"id\" = \"addresses\".\"user_id\";", sel.Build(suite.postgres).SQL())
+}
+
+func (suite *SelectTestSuite) TestCustomJoin() {
+	sel := Select(suite.sessions.C("id"), suite.users.C("email")).
+		From(suite.sessions).
+		Join("STRAIGHT_JOIN", suite.users, suite.sessions.C("user_id"), suite.users.C("id"))
+
+	statement := sel.Build(suite.mysql)
+	assert.Equal(suite.T(), "SELECT `sessions`.`id`, `users`.`email`\nFROM `sessions`\nSTRAIGHT_JOIN `users` ON `sessions`.`user_id` = `users`.`id`;", statement.SQL())
+}
+
+func (suite *SelectTestSuite) TestJoinOrder() {
+	roles := Table("roles", Column("id", BigInt()), Column("user_id", BigInt()))
+	devices := Table("devices", Column("id", BigInt()), Column("session_id", BigInt()))
+
+	base := Select(suite.sessions.C("id")).
+		From(suite.sessions).
+		InnerJoin(suite.users, suite.sessions.C("user_id"), suite.users.C("id"))
+
+	withRoles := base.LeftJoin(roles, roles.C("user_id"), suite.users.C("id"))
+	withDevices := base.InnerJoin(devices, devices.C("session_id"), suite.sessions.C("id"))
+	all := withRoles.LeftJoin(devices, devices.C("session_id"), suite.sessions.C("id"))
+
+	postgres := NewDialect("postgres")
+	assert.Equal(suite.T(), "SELECT sessions.id\n"+
+		"FROM sessions\n"+
+		"INNER JOIN users ON sessions.user_id = users.id\n"+
+		"LEFT OUTER JOIN roles ON roles.user_id = users.id\n"+
+		"LEFT OUTER JOIN devices ON devices.session_id = sessions.id;", all.Build(postgres).SQL())
+
+	// the statements branched from the same base do not share their joins
+	assert.Equal(suite.T(), "SELECT sessions.id\n"+
+		"FROM sessions\n"+
+		"INNER JOIN users ON sessions.user_id = users.id\n"+
+		"INNER JOIN devices ON devices.session_id = sessions.id;", withDevices.Build(postgres).SQL())
+	assert.Equal(suite.T(), "SELECT sessions.id\n"+
+		"FROM sessions\n"+
+		"INNER JOIN users ON sessions.user_id = users.id\n"+
+		"LEFT OUTER JOIN roles ON roles.user_id = users.id;", withRoles.Build(postgres).SQL())
+	assert.Equal(suite.T(), "SELECT sessions.id\n"+
+		"FROM sessions\n"+
+		"INNER JOIN users ON sessions.user_id = users.id;", base.Build(postgres).SQL())
+}
+
+func (suite *SelectTestSuite) TestJoinCondition() {
+	postgres := NewDialect("postgres")
+	sel := Select(suite.sessions.C("id"), suite.users.C("email")).
+		From(suite.sessions).
+		InnerJoin(suite.users, And(
+			Eq(suite.sessions.C("user_id"), suite.users.C("id")),
+			NotEq(suite.users.C("email"), "root@localhost"),
+		)).
+		Where(Gt(suite.sessions.C("id"), 10))
+
+	statement := sel.Build(postgres)
+	assert.Equal(suite.T(), "SELECT sessions.id, users.email\n"+
+		"FROM sessions\n"+
+		"INNER JOIN users ON (sessions.user_id = users.id AND users.email != $1)\n"+
+		"WHERE sessions.id > $2;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{"root@localhost", 10}, statement.Bindings())
+
+	sel = Select(suite.sessions.C("id"), suite.users.C("email")).
+		From(suite.sessions).
+		FullOuterJoin(suite.users, suite.sessions.C("user_id"), suite.users.C("id"))
+
+	statement = sel.Build(postgres)
+	assert.Equal(suite.T(), "SELECT sessions.id, users.email\n"+
+		"FROM sessions\n"+
+		"FULL OUTER JOIN users ON sessions.user_id = users.id;", statement.SQL())
+
+	_, err := BuildStmt(NewCompilerContext(suite.mysql), sel)
+	assert.EqualError(suite.T(), err, "FULL OUTER JOIN is not supported by mysql")
+}
+
+func (suite *SelectTestSuite) TestForUpdate() {
+	sel := Select(suite.sessions.C("id"), suite.users.C("email")).
+		From(suite.sessions).
+		InnerJoin(suite.users, suite.sessions.C("user_id"), suite.users.C("id")).
+		Where(Eq(suite.users.C("id"), 5)).
+		ForUpdate(suite.users)
+
+	statement := sel.Build(NewDialect("postgres"))
+	assert.Equal(suite.T(), "SELECT sessions.id, users.email\n"+
+		"FROM sessions\n"+
+		"INNER JOIN users ON sessions.user_id = users.id\n"+
+		"WHERE users.id = $1\n"+
+		"FOR UPDATE OF users;", statement.SQL())
+
+	statement = Select(suite.users.C("email")).From(suite.users).LimitCount(1).ForUpdate().Build(suite.mysql)
+	assert.Equal(suite.T(), "SELECT `email`\nFROM `users`\nLIMIT 1\nFOR UPDATE;", statement.SQL())
+
+	_, err := BuildStmt(NewCompilerContext(suite.sqlite), sel)
+	assert.EqualError(suite.T(), err, "FOR UPDATE is not supported by sqlite")
+
+	assert.NotContains(suite.T(), sel.Count().Build(suite.postgres).SQL(), "FOR UPDATE")
+}
+
+func (suite *SelectTestSuite) TestLockStrategy() {
+	dispatch := func(skip bool) SelectStmt {
+		strategy := LockNoWait
+		if skip {
+			strategy = LockSkipLocked
+		}
+		return Select(suite.sessions.C("id")).
+			From(suite.sessions).
+			LimitCount(1).
+			ForUpdate().
+			OnLocked(strategy)
+	}
+
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nLIMIT 1\nFOR UPDATE NOWAIT;",
+		dispatch(false).Build(suite.postgres).SQL())
+	assert.Equal(suite.T(), "SELECT `id`\nFROM `sessions`\nLIMIT 1\nFOR UPDATE SKIP LOCKED;",
+		dispatch(true).Build(suite.mysql).SQL())
+
+	locked := dispatch(true)
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nLIMIT 1\nFOR UPDATE;",
+		locked.OnLocked(LockWait).Build(suite.postgres).SQL())
+	// OnLocked returns a copy
+	assert.Contains(suite.T(), locked.Build(suite.postgres).SQL(), "FOR UPDATE SKIP LOCKED;")
+}
+
+func (suite *SelectTestSuite) TestLockWait() {
+	sel := Select(suite.sessions.C("id")).
+		From(suite.sessions).
+		ForUpdate()
+
+	// oracle has no registered dialect, it gets the default one
+	oracle := NewDialect("oracle")
+	assert.Equal(suite.T(), "SELECT id\nFROM sessions\nFOR UPDATE WAIT 5;", sel.Wait(5).Build(oracle).SQL())
+	assert.Equal(suite.T(), "SELECT id\nFROM sessions\nFOR UPDATE NOWAIT;", sel.Wait(5).OnLocked(LockNoWait).Build(oracle).SQL())
+	assert.Equal(suite.T(), "SELECT id\nFROM sessions\nFOR UPDATE WAIT 3;", sel.OnLocked(LockSkipLocked).Wait(3).Build(oracle).SQL())
+
+	_, _, err := sel.Wait(5).ToSQL(suite.postgres)
+	assert.EqualError(suite.T(), err, "FOR UPDATE WAIT is not supported by postgres")
+	_, _, err = sel.Wait(5).ToSQL(suite.mysql)
+	assert.EqualError(suite.T(), err, "FOR UPDATE WAIT is not supported by mysql")
+
+	timeout := 5
+	lock := LockClause{Strength: "UPDATE", Strategy: LockSkipLocked, Timeout: &timeout}
+	_, err = BuildStmt(NewCompilerContext(oracle), lock)
+	assert.EqualError(suite.T(), err, "FOR UPDATE WAIT cannot be combined with NOWAIT or SKIP LOCKED")
+}
+
+func (suite *SelectTestSuite) TestCount() {
+	sel := Select(suite.sessions.C("id"), suite.sessions.C("auth_token")).
+		From(suite.sessions).
+		InnerJoin(suite.users, suite.sessions.C("user_id"), suite.users.C("id")).
+		Where(Eq(suite.users.C("email"), "al@pacino.com")).
+		OrderBy(suite.sessions.C("id")).
+		Limit(10, 5)
+
+	statement := sel.Count().Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT COUNT(*)\nFROM \"sessions\"\nINNER JOIN \"users\" ON \"sessions\".\"user_id\" = \"users\".\"id\"\nWHERE \"users\".\"email\" = $1;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{"al@pacino.com"}, statement.Bindings())
+
+	// the original statement is left untouched
+	assert.Contains(suite.T(), sel.Build(suite.postgres).SQL(), "LIMIT 5 OFFSET 10")
+
+	// only the distinct rows are counted
+	statement = Select(suite.sessions.C("user_id")).
+		From(suite.sessions).
+		Where(suite.sessions.C("id").Gt(3)).
+		Distinct().
+		OrderBy(suite.sessions.C("user_id")).
+		Count().
+		Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT COUNT(*)\nFROM (SELECT DISTINCT sessions.user_id\nFROM sessions\nWHERE sessions.id > ?) AS t;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{3}, statement.Bindings())
+}
+
+func (suite *SelectTestSuite) TestSelectInto() {
+	archive := Table("sessions_archive")
+	sel := Select(suite.sessions.C("id"), suite.sessions.C("auth_token")).
+		Into(archive).
+		From(suite.sessions).
+		Where(Eq(suite.sessions.C("user_id"), 5))
+
+	statement := sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"id\", \"auth_token\"\nINTO \"sessions_archive\"\nFROM \"sessions\"\nWHERE \"user_id\" = $1;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{5}, statement.Bindings())
+
+	_, err := BuildStmt(NewCompilerContext(suite.mysql), sel)
+	assert.EqualError(suite.T(), err, "SELECT INTO is not supported by mysql")
+	_, err = BuildStmt(NewCompilerContext(suite.sqlite), sel)
+	assert.EqualError(suite.T(), err, "SELECT INTO is not supported by sqlite")
+}
+
 func (suite *SelectTestSuite) TestJoin() {
 
 	// inner join
@@ -219,6 +513,12 @@ func (suite *SelectTestSuite) TestJoin() {
 	statement = selCrossJoin.Build(suite.postgres)
 	assert.Equal(suite.T(), "SELECT \"sessions\".\"id\"\nFROM \"sessions\"\nCROSS JOIN \"users\"\nWHERE \"sessions\".\"user_id\" = $1;", statement.SQL())
 	assert.Equal(suite.T(), []interface{}{5}, statement.Bindings())
+
+	// no ON clause, even between tables that have no foreign key
+	tags := Table("tags", Column("name", Varchar()))
+	statement = Select(suite.users.C("email"), tags.C("name")).From(suite.users).CrossJoin(tags).Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT users.email, tags.name\nFROM users\nCROSS JOIN tags;", statement.SQL())
+	assert.NotContains(suite.T(), statement.SQL(), " ON ")
 }
 
 func (suite *SelectTestSuite) TestGroupByHaving() {
@@ -241,6 +541,181 @@ func (suite *SelectTestSuite) TestGroupByHaving() {
 	assert.Equal(suite.T(), []interface{}{4}, statement.Bindings())
 }
 
+func (suite *SelectTestSuite) TestGroupByHavingDistinct() {
+	countUsers := CountDistinct(suite.sessions.C("user_id"))
+	sel := Select(suite.sessions.C("auth_token"), countUsers).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("auth_token")).
+		Having(countUsers, ">", 1)
+
+	var statement *Stmt
+	statement = sel.Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT auth_token, COUNT(DISTINCT user_id)\nFROM sessions\nGROUP BY auth_token\nHAVING COUNT(DISTINCT user_id) > ?;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{1}, statement.Bindings())
+
+	statement = sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"auth_token\", COUNT(DISTINCT \"user_id\")\nFROM \"sessions\"\nGROUP BY \"auth_token\"\nHAVING COUNT(DISTINCT \"user_id\") > $1;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{1}, statement.Bindings())
+}
+
+func (suite *SelectTestSuite) TestMultipleHaving() {
+	sel := Select(suite.sessions.C("user_id"), Count(suite.sessions.C("id"))).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("user_id")).
+		Having(Count(suite.sessions.C("id")), ">", 1).
+		Having(Max(suite.sessions.C("id")), "<", 100)
+
+	var statement *Stmt
+	statement = sel.Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT user_id, COUNT(id)\nFROM sessions\nGROUP BY user_id\nHAVING COUNT(id) > ? AND MAX(id) < ?;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{1, 100}, statement.Bindings())
+
+	statement = sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"user_id\", COUNT(\"id\")\nFROM \"sessions\"\nGROUP BY \"user_id\"\nHAVING COUNT(\"id\") > $1 AND MAX(\"id\") < $2;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{1, 100}, statement.Bindings())
+}
+
+func (suite *SelectTestSuite) TestStrictGroupBy() {
+	context := func() *CompilerContext {
+		context := NewCompilerContext(suite.postgres)
+		context.Strict = true
+		return context
+	}
+
+	sel := Select(suite.sessions.C("user_id"), As(Count(suite.sessions.C("id")), "total")).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("user_id"))
+	_, err := BuildStmt(context(), sel)
+	assert.Nil(suite.T(), err)
+
+	sel = Select(suite.sessions.C("user_id"), suite.sessions.C("auth_token"), As(suite.sessions.C("id"), "sid"), Count(suite.sessions.C("id"))).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("user_id"))
+	_, err = BuildStmt(context(), sel)
+	assert.EqualError(suite.T(), err, "Selected columns must appear in the GROUP BY clause or be aggregated: \"auth_token\", \"id\"")
+
+	// the check is only run in strict mode
+	_, err = BuildStmt(NewCompilerContext(suite.mysql), sel)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *SelectTestSuite) TestGroupByExpressions() {
+	postgres := NewDialect("postgres")
+	events := Table(
+		"events",
+		Column("id", Int()),
+		Column("kind", Varchar()),
+		Column("created_at", Timestamp()),
+	)
+
+	day := SQLText("date_trunc('day', created_at)")
+	statement := Select(day, Count(events.C("id"))).
+		From(events).
+		GroupBy(day).
+		Build(postgres)
+	assert.Equal(suite.T(), "SELECT date_trunc('day', created_at), COUNT(id)\n"+
+		"FROM events\n"+
+		"GROUP BY date_trunc('day', created_at);", statement.SQL())
+
+	kind := As(events.C("kind"), "event_kind")
+	sel := Select(kind, Count(events.C("id"))).
+		From(events).
+		GroupBy(kind.Ref(), events.C("created_at"))
+	context := NewCompilerContext(postgres)
+	context.Strict = true
+	statement, err := BuildStmt(context, sel)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "SELECT kind AS event_kind, COUNT(id)\n"+
+		"FROM events\n"+
+		"GROUP BY event_kind, created_at;", statement.SQL())
+}
+
+func (suite *SelectTestSuite) TestGroupByAll() {
+	events := Table(
+		"events",
+		Column("id", Int()),
+		Column("user_id", Int()),
+		Column("kind", Varchar()),
+	)
+	sel := Select(events.C("user_id"), As(events.C("kind"), "k"), Count(events.C("id")), As(Max(events.C("id")), "last")).
+		From(events).
+		GroupByAll()
+
+	// the default dialect, for the engines that support it natively
+	assert.Equal(suite.T(), "SELECT user_id, kind AS k, COUNT(id), MAX(id) AS last\n"+
+		"FROM events\n"+
+		"GROUP BY ALL;", sel.Build(NewDialect("duckdb")).SQL())
+
+	assert.Equal(suite.T(), "SELECT user_id, kind AS k, COUNT(id), MAX(id) AS last\n"+
+		"FROM events\n"+
+		"GROUP BY user_id, kind;", sel.Build(NewDialect("postgres")).SQL())
+	assert.Equal(suite.T(), "SELECT `user_id`, `kind` AS `k`, COUNT(`id`), MAX(`id`) AS `last`\n"+
+		"FROM `events`\n"+
+		"GROUP BY `user_id`, `kind`;", sel.Build(suite.mysql).SQL())
+
+	// with only aggregates, nothing is grouped
+	statement := Select(Count(events.C("id"))).From(events).GroupByAll().Build(NewDialect("sqlite3"))
+	assert.Equal(suite.T(), "SELECT COUNT(id)\nFROM events;", statement.SQL())
+
+	// the aggregates nested in functions and CASE expressions are not
+	// grouped, nor are the bound values
+	statement = Select(
+		events.C("user_id"),
+		As(Func("COALESCE", Max(events.C("id")), 0), "last"),
+		As(Case().When(Gt(Count(events.C("id")), 10), "busy").Else("quiet"), "load"),
+		As(Bind("web"), "source"),
+	).From(events).GroupByAll().Build(NewDialect("postgres"))
+	assert.Equal(suite.T(), "SELECT user_id, COALESCE(MAX(id), $1) AS last, "+
+		"CASE WHEN COUNT(id) > $2 THEN $3 ELSE $4 END AS load, $5 AS source\n"+
+		"FROM events\n"+
+		"GROUP BY user_id;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{0, 10, "busy", "quiet", "web"}, statement.Bindings())
+
+	// an expression is grouped by its position, its binds are bound once
+	statement = Select(As(Func("LEFT", events.C("kind"), Bind(3)), "prefix"), events.C("user_id"), Count(events.C("id"))).
+		From(events).
+		GroupByAll().
+		Build(NewDialect("postgres"))
+	assert.Equal(suite.T(), "SELECT LEFT(kind, $1) AS prefix, user_id, COUNT(id)\n"+
+		"FROM events\n"+
+		"GROUP BY 1, user_id;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{3}, statement.Bindings())
+
+	_, _, err := sel.GroupBy(events.C("kind")).ToSQL(NewDialect("duckdb"))
+	assert.EqualError(suite.T(), err, "GROUP BY ALL cannot be combined with GROUP BY columns")
+}
+
+func (suite *SelectTestSuite) TestHavingAlias() {
+	total := As(Count(suite.sessions.C("id")), "total")
+	sel := Select(suite.sessions.C("user_id"), total).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("user_id")).
+		Having(total.Ref(), ">", 4)
+
+	var statement *Stmt
+	statement = sel.Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT user_id, COUNT(id) AS total\nFROM sessions\nGROUP BY user_id\nHAVING total > ?;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{4}, statement.Bindings())
+
+	statement = sel.Build(suite.mysql)
+	assert.Equal(suite.T(), "SELECT `user_id`, COUNT(`id`) AS `total`\nFROM `sessions`\nGROUP BY `user_id`\nHAVING `total` > ?;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{4}, statement.Bindings())
+
+	assert.Panics(suite.T(), func() { sel.Build(suite.postgres) })
+}
+
+func (suite *SelectTestSuite) TestColumnNames() {
+	sel := Select(
+		suite.sessions.C("id"),
+		As(suite.sessions.C("auth_token"), "token"),
+		As(Count(suite.sessions.C("user_id")), "user_count"),
+		Max(suite.sessions.C("id")),
+	).From(suite.sessions)
+
+	assert.Equal(suite.T(), []string{"id", "token", "user_count", ""}, sel.ColumnNames())
+	assert.Equal(suite.T(), []string{}, Select().ColumnNames())
+}
+
 func (suite *SelectTestSuite) TestAlias() {
 	sessionA := Alias("newname", suite.sessions)
 	sel := Select(sessionA.C("id")).From(sessionA)
@@ -265,6 +740,28 @@ LEFT OUTER JOIN "sessions" AS "newname" ON "u"."id" = "newname"."user_id"
 WHERE "newname"."auth_token" = $1;`, st.SQL())
 }
 
+func (suite *SelectTestSuite) TestTableSample() {
+	sample := suite.users.TableSample("SYSTEM", 10)
+	sel := Select(sample.C("email")).
+		From(sample).
+		Where(Eq(sample.C("id"), 5))
+
+	statement := sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"email\"\nFROM \"users\" TABLESAMPLE SYSTEM (10)\nWHERE \"id\" = $1;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{5}, statement.Bindings())
+
+	sel = Select(suite.users.C("email")).From(suite.users.TableSample("BERNOULLI", 0.5))
+	assert.Equal(suite.T(), "SELECT \"email\"\nFROM \"users\" TABLESAMPLE BERNOULLI (0.5);", sel.Build(suite.postgres).SQL())
+
+	assert.Panics(suite.T(), func() { sel.Build(suite.mysql) })
+	assert.Panics(suite.T(), func() { sel.Build(suite.sqlite) })
+
+	repeatable := sample.Repeatable(42)
+	sel = Select(repeatable.C("email")).From(repeatable)
+	assert.Equal(suite.T(), "SELECT \"email\"\nFROM \"users\" TABLESAMPLE SYSTEM (10) REPEATABLE (42);", sel.Build(suite.postgres).SQL())
+	assert.Nil(suite.T(), sample.Seed)
+}
+
 func (suite *SelectTestSuite) TestGuessJoinOnClause() {
 	t1 := Table(
 		"t1",
@@ -312,6 +809,180 @@ func (suite *SelectTestSuite) TestGuessJoinOnClause() {
 	})
 }
 
+func (suite *SelectTestSuite) TestSelectLiterals() {
+	sql, binds := asDefSQLBinds(Select(Bind(1), Bind("x")))
+	assert.Equal(suite.T(), "SELECT ?, ?", sql)
+	assert.Equal(suite.T(), []interface{}{1, "x"}, binds)
+
+	sql, binds = asSQLBinds(Select(Bind(1), Bind("x"), NullLiteral()), NewDialect("postgres"))
+	assert.Equal(suite.T(), "SELECT $1, $2, NULL", sql)
+	assert.Equal(suite.T(), []interface{}{1, "x"}, binds)
+}
+
+func (suite *SelectTestSuite) TestOrderByExpressions() {
+	total := As(Count(suite.sessions.C("id")), "total")
+	sel := Select(suite.sessions.C("user_id"), total).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("user_id")).
+		OrderBy(
+			Desc(total.Ref()),
+			Asc(Max(suite.sessions.C("auth_token"))).NullsLast(),
+			suite.sessions.C("user_id"),
+		)
+
+	statement := sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"user_id\", COUNT(\"id\") AS \"total\"\nFROM \"sessions\"\nGROUP BY \"user_id\"\nORDER BY \"total\" DESC, MAX(\"auth_token\") ASC NULLS LAST, \"user_id\" ASC;", statement.SQL())
+
+	statement = sel.OrderBy(IntLiteral(2), Desc(suite.sessions.C("user_id"))).Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT user_id, COUNT(id) AS total\nFROM sessions\nGROUP BY user_id\nORDER BY 2, user_id DESC;", statement.SQL())
+
+	_, err := BuildStmt(NewCompilerContext(suite.mysql), sel)
+	assert.EqualError(suite.T(), err, "NULLS LAST is not supported by mysql")
+}
+
+func (suite *SelectTestSuite) TestOrderByUsing() {
+	sel := Select(suite.sessions.C("id")).
+		From(suite.sessions).
+		OrderBy(Asc(suite.sessions.C("user_id")).Using(">").NullsLast(), Desc(suite.sessions.C("id")).Using("<"))
+
+	statement := sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nORDER BY \"user_id\" USING > NULLS LAST, \"id\" USING <;", statement.SQL())
+
+	_, err := BuildStmt(NewCompilerContext(suite.sqlite), sel)
+	assert.EqualError(suite.T(), err, "ORDER BY ... USING is not supported by this compiler")
+}
+
+func (suite *SelectTestSuite) TestLimitOffset() {
+	sel := Select(suite.sessions.C("id")).From(suite.sessions)
+
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nLIMIT 10;", sel.LimitCount(10).Build(suite.postgres).SQL())
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nOFFSET 20;", sel.Offset(20).Build(suite.postgres).SQL())
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nLIMIT 10 OFFSET 20;", sel.Offset(20).LimitCount(10).Build(suite.postgres).SQL())
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nLIMIT 10 OFFSET 20;", sel.Limit(20, 10).Build(suite.postgres).SQL())
+
+	assert.Equal(suite.T(), "SELECT `id`\nFROM `sessions`\nLIMIT 18446744073709551615 OFFSET 20;", sel.Offset(20).Build(suite.mysql).SQL())
+	assert.Equal(suite.T(), "SELECT `id`\nFROM `sessions`\nLIMIT 10;", sel.LimitCount(10).Build(suite.mysql).SQL())
+	assert.Equal(suite.T(), "SELECT id\nFROM sessions\nLIMIT -1 OFFSET 20;", sel.Offset(20).Build(suite.sqlite).SQL())
+
+	_, err := BuildStmt(NewCompilerContext(suite.sqlite), sel.Offset(-2))
+	assert.EqualError(suite.T(), err, "Invalid negative offset: -2")
+
+	context := NewCompilerContext(suite.postgres)
+	context.FetchFirst = true
+	statement, err := BuildStmt(context, sel.LimitCount(5))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nFETCH FIRST 5 ROWS ONLY;", statement.SQL())
+}
+
+func (suite *SelectTestSuite) TestDistinctOn() {
+	sel := Select(suite.sessions.C("user_id"), suite.sessions.C("auth_token")).
+		From(suite.sessions).
+		Distinct()
+	assert.Equal(suite.T(), "SELECT DISTINCT \"user_id\", \"auth_token\"\nFROM \"sessions\";", sel.Build(suite.postgres).SQL())
+
+	sel = Select(suite.sessions.C("user_id"), suite.sessions.C("auth_token")).
+		From(suite.sessions).
+		DistinctOn(suite.sessions.C("user_id")).
+		OrderBy(suite.sessions.C("user_id"), suite.sessions.C("id")).Desc()
+	assert.Equal(suite.T(), "SELECT DISTINCT ON (\"user_id\") \"user_id\", \"auth_token\"\nFROM \"sessions\"\nORDER BY \"user_id\", \"id\" DESC;", sel.Build(suite.postgres).SQL())
+
+	sel = sel.DistinctOn(suite.sessions.C("user_id"), suite.sessions.C("auth_token"))
+	assert.Equal(suite.T(), "SELECT DISTINCT ON (\"user_id\", \"auth_token\") \"user_id\", \"auth_token\"\nFROM \"sessions\"\nORDER BY \"user_id\", \"id\" DESC;", sel.Build(suite.postgres).SQL())
+
+	_, err := BuildStmt(NewCompilerContext(suite.mysql), sel)
+	assert.EqualError(suite.T(), err, "DISTINCT ON is not supported by this compiler")
+	_, err = BuildStmt(NewCompilerContext(suite.sqlite), sel)
+	assert.EqualError(suite.T(), err, "DISTINCT ON is not supported by this compiler")
+}
+
+func (suite *SelectTestSuite) TestToSQL() {
+	sql, binds, err := Select(suite.sessions.C("id")).
+		From(suite.sessions).
+		Where(suite.sessions.C("user_id").Eq(5)).
+		ToSQL(suite.postgres)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nWHERE \"user_id\" = $1;", sql)
+	assert.Equal(suite.T(), []interface{}{5}, binds)
+
+	sql, binds, err = Select().From(suite.sessions).ToSQL(suite.postgres)
+	assert.EqualError(suite.T(), err, "Select has no columns")
+	assert.Equal(suite.T(), "", sql)
+	assert.Nil(suite.T(), binds)
+}
+
+func (suite *SelectTestSuite) TestFromSubquery() {
+	recent := Alias("recent", Subquery(
+		Select(suite.sessions.C("user_id"), As(Count(suite.sessions.C("id")), "total")).
+			From(suite.sessions).
+			Where(suite.sessions.C("id").Gt(100)).
+			GroupBy(suite.sessions.C("user_id"))))
+
+	assert.Equal(suite.T(), "recent", recent.C("total").Table)
+	assert.Equal(suite.T(), 2, len(recent.All()))
+	assert.Panics(suite.T(), func() { recent.C("id") })
+
+	sel := Select(recent.C("user_id"), recent.C("total")).
+		From(recent).
+		Where(recent.C("total").Gt(3))
+
+	statement := sel.Build(suite.postgres)
+	assert.Equal(suite.T(), `SELECT "user_id", "total"
+FROM (SELECT "sessions"."user_id", COUNT("sessions"."id") AS "total"
+FROM "sessions"
+WHERE "sessions"."id" > $1
+GROUP BY "user_id") AS "recent"
+WHERE "total" > $2;`, statement.SQL())
+	assert.Equal(suite.T(), []interface{}{100, 3}, statement.Bindings())
+
+	sel = Select(suite.users.C("email"), recent.C("total")).
+		From(suite.users).
+		InnerJoin(recent, suite.users.C("id"), recent.C("user_id"))
+	statement = sel.Build(suite.sqlite)
+	assert.Equal(suite.T(), `SELECT users.email, recent.total
+FROM users
+INNER JOIN (SELECT sessions.user_id, COUNT(sessions.id) AS total
+FROM sessions
+WHERE sessions.id > ?
+GROUP BY user_id) AS recent ON users.id = recent.user_id;`, statement.SQL())
+}
+
+func (suite *SelectTestSuite) TestAppendRaw() {
+	sel := Select(suite.sessions.C("id")).
+		From(suite.sessions).
+		Where(suite.sessions.C("user_id").Eq(5)).
+		LimitCount(10).
+		AppendRaw(RawTrailing, "FOR UPDATE OF sessions SKIP LOCKED").
+		AppendRaw(RawAfterFrom, "FOR SYSTEM_TIME AS OF ?", "2016-01-01")
+
+	statement := sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nFOR SYSTEM_TIME AS OF $1\nWHERE \"user_id\" = $2\nLIMIT 10\nFOR UPDATE OF sessions SKIP LOCKED;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{"2016-01-01", 5}, statement.Bindings())
+
+	statement = Select(suite.sessions.C("id")).
+		From(suite.sessions).
+		Where(suite.sessions.C("user_id").Eq(5)).
+		AppendRaw(RawAfterWhere, "AND id > ?", 3).
+		Build(suite.sqlite)
+	assert.Equal(suite.T(), "SELECT id\nFROM sessions\nWHERE user_id = ?\nAND id > ?;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{5, 3}, statement.Bindings())
+}
+
+func (suite *SelectTestSuite) TestFetchFirst() {
+	sel := Select(suite.sessions.C("id")).
+		From(suite.sessions).
+		OrderBy(suite.sessions.C("id")).
+		Limit(20, 10)
+
+	statement := sel.Build(suite.postgres)
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nORDER BY \"id\" ASC\nLIMIT 10 OFFSET 20;", statement.SQL())
+
+	context := NewCompilerContext(suite.postgres)
+	context.FetchFirst = true
+	statement, err := BuildStmt(context, sel)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "SELECT \"id\"\nFROM \"sessions\"\nORDER BY \"id\" ASC\nOFFSET 20 ROWS\nFETCH FIRST 10 ROWS ONLY;", statement.SQL())
+}
+
 func (suite *SelectTestSuite) TestMakeJoinOnClause() {
 	assert.Panics(suite.T(), func() {
 		MakeJoinOnClause(TableElem{}, TableElem{}, And(), And(), And())
@@ -1,8 +1,13 @@
 package qb
 
 import (
+	"crypto/sha256"
+	"database/sql/driver"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 const defaultDelimiter = "\n"
@@ -17,10 +22,148 @@ func Statement() *Stmt {
 	}
 }
 
+// BuildStmt compiles a statement, or any clause, within the given context
+// and returns the resulting Stmt.
+// Contrary to the Build() function of the statements, it does not panic if
+// the compilation fails but returns the first error that was raised.
+func BuildStmt(context *CompilerContext, clause Clause) (*Stmt, error) {
+	sql := clause.Accept(context)
+	if context.CheckBinds {
+		if count := countPlaceholders(sql); count != len(context.Binds) {
+			context.AddError(fmt.Errorf(
+				"The SQL has %d placeholders for %d binds", count, len(context.Binds)))
+		}
+	}
+	if context.MaxBinds > 0 && len(context.Binds) > context.MaxBinds {
+		context.AddError(fmt.Errorf(
+			"The statement has %d binds, more than the maximum of %d", len(context.Binds), context.MaxBinds))
+	}
+	if len(context.Errors) > 0 {
+		return nil, context.Errors[0]
+	}
+
+	statement := Statement()
+	statement.driver = context.Dialect.Driver()
+	statement.AddSQLClause(sql)
+	statement.AddBinding(context.Binds...)
+	if sel, ok := clause.(SelectStmt); ok {
+		statement.SetColumns(sel.ColumnNames()...)
+	}
+	if names, ok := context.Vars[varBindNames].([]string); ok {
+		statement.SetBindNames(names...)
+	}
+	return statement, nil
+}
+
+// countPlaceholders counts the '?' and '$n' placeholders of a SQL text that
+// are not in a quoted string or identifier. A '$n' placeholder used several
+// times, with DedupBinds, is counted once
+func countPlaceholders(sql string) int {
+	var (
+		count    int
+		quote    rune
+		dollar   bool
+		number   string
+		numbered = map[string]bool{}
+	)
+	for _, r := range sql + " " {
+		if dollar {
+			if unicode.IsDigit(r) {
+				number += string(r)
+				continue
+			}
+			dollar = false
+			if number != "" {
+				numbered[number] = true
+				number = ""
+			}
+		}
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			count++
+		case r == '$':
+			dollar = true
+		}
+	}
+	return count + len(numbered)
+}
+
+// mustBuildStmt compiles a clause with the given dialect and returns the
+// resulting Stmt. It panics if the compilation fails
+func mustBuildStmt(dialect Dialect, clause Clause) *Stmt {
+	statement, err := BuildStmt(NewCompilerContext(dialect), clause)
+	if err != nil {
+		panic(err)
+	}
+	return statement
+}
+
+// toSQL compiles a clause with the given dialect and returns the resulting
+// SQL and bindings, or the first compilation error
+func toSQL(dialect Dialect, clause Clause) (string, []interface{}, error) {
+	statement, err := BuildStmt(NewCompilerContext(dialect), clause)
+	if err != nil {
+		return "", nil, err
+	}
+	return statement.SQL(), statement.Bindings(), nil
+}
+
+// canonicalSQL compiles a clause with the given dialect and returns its
+// canonical SQL
+func canonicalSQL(dialect Dialect, clause Clause) (string, error) {
+	statement, err := BuildStmt(NewCompilerContext(dialect), clause)
+	if err != nil {
+		return "", err
+	}
+	return lowerSQL(normalizeSQL(statement.SQL())), nil
+}
+
+// validate compiles a clause with the given dialect in strict and safe mode,
+// checking the binds, and returns all the errors found. A panic of the
+// compilation is returned as an error too
+func validate(dialect Dialect, clause Clause) (errs []error) {
+	defer func() {
+		if r := recover(); r != nil {
+			errs = append(errs, fmt.Errorf("%v", r))
+		}
+	}()
+	context := NewCompilerContext(dialect)
+	context.Strict = true
+	context.Safe = true
+	context.CheckBinds = true
+	BuildStmt(context, clause)
+	return context.Errors
+}
+
+// stmtString compiles a statement with the default dialect, for inspection.
+// It does not panic if the statement is incomplete or invalid, but returns
+// an error marker
+func stmtString(clause Clause) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("<invalid statement: %v>", r)
+		}
+	}()
+	statement, err := BuildStmt(NewCompilerContext(NewDialect("default")), clause)
+	if err != nil {
+		return fmt.Sprintf("<invalid statement: %v>", err)
+	}
+	return statement.SQL()
+}
+
 // Stmt is the base abstraction for all sql queries
 type Stmt struct {
 	clauses      []string
 	bindings     []interface{}
+	columns      []string
+	bindNames    []string
+	driver       string
 	delimiter    string
 	bindingIndex int
 }
@@ -54,6 +197,16 @@ func (s *Stmt) AddBinding(bindings ...interface{}) {
 	}
 }
 
+// SetColumns sets the names of the columns returned by the query
+func (s *Stmt) SetColumns(columns ...string) {
+	s.columns = columns
+}
+
+// Columns returns the names of the columns returned by the query
+func (s *Stmt) Columns() []string {
+	return s.columns
+}
+
 // SQLClauses returns all clauses of current query
 func (s *Stmt) SQLClauses() []string {
 	return s.clauses
@@ -64,6 +217,58 @@ func (s *Stmt) Bindings() []interface{} {
 	return s.bindings
 }
 
+// NamedArgPlaceholder renders the @argN placeholders of the pgx named
+// arguments. Set it as the Placeholder of the CompilerContext, and pass the
+// NamedArgs() of the statement to pgx
+func NamedArgPlaceholder(index int) string {
+	return fmt.Sprintf("@%s", namedArg(index))
+}
+
+func namedArg(index int) string {
+	return fmt.Sprintf("arg%d", index)
+}
+
+// NamedArgs returns the bindings of the query by their NamedArgPlaceholder
+// name
+func (s *Stmt) NamedArgs() map[string]interface{} {
+	args := make(map[string]interface{}, len(s.bindings))
+	for i, value := range s.bindings {
+		args[namedArg(i+1)] = value
+	}
+	return args
+}
+
+// SetBindNames sets the names of the bindings, in the same order. An empty
+// name leaves the binding unnamed
+func (s *Stmt) SetBindNames(names ...string) {
+	s.bindNames = names
+}
+
+// NamedBindings returns the bindings of the query by the name of the column
+// they are compared or assigned to, for logging and tracing. The SQL keeps
+// its positional placeholders. A name used by several bindings gets a _2,
+// _3... suffix, and the unnamed bindings are named like in NamedArgs()
+func (s *Stmt) NamedBindings() map[string]interface{} {
+	args := make(map[string]interface{}, len(s.bindings))
+	counts := map[string]int{}
+	for i, value := range s.bindings {
+		name := ""
+		if i < len(s.bindNames) {
+			name = s.bindNames[i]
+		}
+		if name == "" {
+			args[namedArg(i+1)] = value
+			continue
+		}
+		counts[name]++
+		if counts[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, counts[name])
+		}
+		args[name] = value
+	}
+	return args
+}
+
 // SQL returns the query struct sql statement
 func (s *Stmt) SQL() string {
 	if len(s.clauses) > 0 {
@@ -73,3 +278,208 @@ func (s *Stmt) SQL() string {
 
 	return ""
 }
+
+// Embed returns the statement as a clause, to be spliced into another
+// statement: Where(In(users.C("id"), embedded))
+// Its placeholders become the ones of the dialect of the new statement,
+// numbered in position, and its bindings are merged in the same order. A
+// '$n' placeholder used several times is bound each time.
+// Only the placeholders of the dialect the statement was built with are
+// rewritten: '$n' for postgres, '?' for the others. So the '?' operators of
+// postgres are kept, but a '$n' in a mysql statement is not a placeholder.
+// The placeholders set by CompilerContext.Placeholder are not supported.
+// It returns an error if a placeholder has no binding
+func (s *Stmt) Embed() (EmbedClause, error) {
+	sql := strings.Join(s.clauses, s.delimiter)
+	numbered := s.driver == "postgres"
+	var (
+		embed EmbedClause
+		part  strings.Builder
+		quote byte
+		next  int
+	)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?' && !numbered:
+			if next >= len(s.bindings) {
+				return EmbedClause{}, fmt.Errorf("The SQL has more placeholders than its %d binds", len(s.bindings))
+			}
+			embed.Parts = append(embed.Parts, part.String())
+			embed.Binds = append(embed.Binds, s.bindings[next])
+			part.Reset()
+			next++
+			continue
+		case c == '$' && numbered && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			j := i + 1
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			index, _ := strconv.Atoi(sql[i+1 : j])
+			if index < 1 || index > len(s.bindings) {
+				return EmbedClause{}, fmt.Errorf("The SQL placeholder $%d has no bind", index)
+			}
+			embed.Parts = append(embed.Parts, part.String())
+			embed.Binds = append(embed.Binds, s.bindings[index-1])
+			part.Reset()
+			i = j - 1
+			continue
+		}
+		part.WriteByte(c)
+	}
+	embed.Parts = append(embed.Parts, part.String())
+	return embed, nil
+}
+
+// Interpolate returns the SQL of the statement with its placeholders replaced
+// by the literals of their bindings, for logging and debugging.
+// WARNING: the result is meant to be read, not run. The literals are quoted
+// by the dialect but are not guaranteed to be safe against injections, nor
+// to be converted like the driver would do.
+// It returns an error if a placeholder has no binding.
+func (s *Stmt) Interpolate() (string, error) {
+	dialect := NewDialect(s.driver)
+	sql := s.SQL()
+	var (
+		b     strings.Builder
+		quote byte
+		next  int
+	)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			if next >= len(s.bindings) {
+				return "", fmt.Errorf("The SQL has more placeholders than its %d binds", len(s.bindings))
+			}
+			b.WriteString(interpolateValue(dialect, s.bindings[next]))
+			next++
+			continue
+		case c == '$' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			j := i + 1
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			index, _ := strconv.Atoi(sql[i+1 : j])
+			if index < 1 || index > len(s.bindings) {
+				return "", fmt.Errorf("The SQL placeholder $%d has no bind", index)
+			}
+			b.WriteString(interpolateValue(dialect, s.bindings[index-1]))
+			i = j - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), nil
+}
+
+// interpolateValue returns the SQL literal of a bind value
+func interpolateValue(dialect Dialect, value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			value = v
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return dialect.QuoteLiteral(v)
+	case []byte:
+		return dialect.QuoteLiteral(string(v))
+	case bool:
+		switch dialect.Driver() {
+		case "mysql", "sqlite3":
+			if v {
+				return "1"
+			}
+			return "0"
+		}
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return dialect.QuoteLiteral(v.Format("2006-01-02 15:04:05.999999999Z07:00"))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	}
+	return dialect.QuoteLiteral(fmt.Sprint(value))
+}
+
+// StructuralKey returns a key of the statement that is suitable to cache
+// prepared statements. It depends on the dialect driver the statement was
+// built for and on its SQL, but not on its whitespaces: two statements that
+// only differ by their formatting get the same key
+func (s *Stmt) StructuralKey() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s", s.driver, normalizeSQL(s.SQL()))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// normalizeSQL collapses the whitespaces of a SQL text that are not in a
+// quoted string or identifier, and drops the ones around parentheses and
+// commas
+func normalizeSQL(sql string) string {
+	var (
+		b       strings.Builder
+		quote   rune
+		space   bool
+		last    rune
+		nospace = "(),;"
+	)
+	for _, r := range sql {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		}
+		if space && last != 0 && !strings.ContainsRune(nospace, last) && !strings.ContainsRune(nospace, r) {
+			b.WriteRune(' ')
+		}
+		space = false
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// lowerSQL lowercases a SQL text, except its quoted strings and identifiers
+func lowerSQL(sql string) string {
+	var (
+		b     strings.Builder
+		quote rune
+	)
+	for _, r := range sql {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		default:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -1,6 +1,8 @@
 package qb
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -48,3 +50,336 @@ func TestStatementWithCustomDelimiter(t *testing.T) {
 	assert.Equal(t, []interface{}{5}, statement.Bindings())
 	assert.Equal(t, "SELECT name FROM user WHERE id = ?;", statement.SQL())
 }
+
+func TestBuildStmt(t *testing.T) {
+	users := Table("users", Column("id", Int()))
+
+	statement, err := BuildStmt(
+		NewCompilerContext(NewDialect("default")),
+		Select(users.C("id")).From(users).Where(users.C("id").Eq(5)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE id = ?;", statement.SQL())
+	assert.Equal(t, []interface{}{5}, statement.Bindings())
+	assert.Equal(t, []string{"id"}, statement.Columns())
+
+	statement, err = BuildStmt(
+		NewCompilerContext(NewDialect("default")),
+		Upsert(users).Values(map[string]interface{}{"id": 5}))
+	assert.Nil(t, statement)
+	assert.EqualError(t, err, "Upsert is not Implemented in this compiler")
+}
+
+func TestStmtString(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+
+	sel := Select(users.C("id")).From(users).Where(users.C("email").Eq("al@pacino.com"))
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE email = ?;", fmt.Sprintf("%s", sel))
+	assert.Equal(t, "DELETE FROM users;", fmt.Sprintf("%s", Delete(users)))
+	assert.Equal(t, "INSERT INTO users DEFAULT VALUES;", fmt.Sprintf("%s", Insert(users).DefaultValues()))
+	assert.Equal(t, "UPDATE users\nSET id = ?;", fmt.Sprintf("%s", Update(users).Values(map[string]interface{}{"id": 1})))
+
+	// incomplete or invalid statements do not panic
+	assert.Equal(t, "<invalid statement: Select has no columns>", fmt.Sprintf("%s", Select().From(users)))
+	assert.Equal(t, "<invalid statement: Upsert is not Implemented in this compiler>", fmt.Sprintf("%s", Upsert(users)))
+	assert.Contains(t, fmt.Sprintf("%s", Select(users.C("id"))), "SELECT ")
+}
+
+func TestStructuralKey(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+	postgres := NewDialect("postgres")
+
+	a := Select(users.C("id")).From(users).Where(SQLTextBinds("email = ?", "a@b.c")).Build(postgres)
+	b := Select(users.C("id")).From(users).Where(SQLTextBinds("\temail  =\n ? ", "x@y.z")).Build(postgres)
+	assert.NotEqual(t, a.SQL(), b.SQL())
+	assert.Equal(t, a.StructuralKey(), b.StructuralKey())
+
+	c := Select(users.C("id"), users.C("email")).From(users).Where(SQLTextBinds("email = ?", "a@b.c")).Build(postgres)
+	assert.NotEqual(t, a.StructuralKey(), c.StructuralKey())
+
+	// the dialect is part of the key
+	d := Select(users.C("id")).From(users).Where(SQLTextBinds("email = ?", "a@b.c")).Build(NewDialect("sqlite3"))
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE email = ?;", d.SQL())
+	e := Select(users.C("id")).From(users).Where(SQLTextBinds("email = ?", "a@b.c")).Build(NewDialect("mysql"))
+	assert.Equal(t, d.SQL(), e.SQL())
+	assert.NotEqual(t, d.StructuralKey(), e.StructuralKey())
+
+	// the quoted strings are kept as is
+	f := Select(SQLText("'a  b'")).Build(postgres)
+	g := Select(SQLText("'a b'")).Build(postgres)
+	assert.NotEqual(t, f.StructuralKey(), g.StructuralKey())
+
+	assert.Equal(t, "SELECT a,b FROM t WHERE(x = 1);", normalizeSQL("SELECT a , b\nFROM t\nWHERE ( x  =  1 ) ;"))
+}
+
+func TestCanonicalSQL(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("email", Varchar()))
+	postgres := NewDialect("postgres")
+
+	a, err := Select(users.C("id")).From(users).Where(SQLTextBinds("email = ?", "a@b.c")).CanonicalSQL(postgres)
+	assert.Nil(t, err)
+	b, err := Select(users.C("id")).From(users).Where(SQLTextBinds("\tEMAIL  =\n ? ", "x@y.z")).CanonicalSQL(postgres)
+	assert.Nil(t, err)
+	assert.Equal(t, "select id from users where email = $1;", a)
+	assert.Equal(t, a, b)
+
+	// the quoted strings and identifiers keep their case
+	c, _ := Select(SQLText(`'A' AS "Total"`)).CanonicalSQL(postgres)
+	assert.Equal(t, `select 'A' as "Total";`, c)
+
+	_, err = Delete(users).CanonicalSQL(postgres)
+	assert.Nil(t, err)
+	_, err = Select(users.C("id")).From(users).ForUpdate().CanonicalSQL(NewDialect("sqlite3"))
+	assert.EqualError(t, err, "FOR UPDATE is not supported by sqlite")
+}
+
+func TestSafeMode(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("active", Boolean()))
+	safeBuild := func(clause Clause) (*Stmt, error) {
+		context := NewCompilerContext(NewDialect("postgres"))
+		context.Safe = true
+		return BuildStmt(context, clause)
+	}
+
+	upd := Update(users).Values(map[string]interface{}{"active": false})
+	del := Delete(users)
+
+	_, err := safeBuild(del)
+	assert.EqualError(t, err, "Delete without WHERE clause, use Unsafe() to delete all the rows")
+	_, err = safeBuild(upd)
+	assert.EqualError(t, err, "Update without WHERE clause, use Unsafe() to update all the rows")
+
+	statement, err := safeBuild(del.Unsafe())
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE FROM users;", statement.SQL())
+	statement, err = safeBuild(upd.Unsafe())
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE users\nSET active = $1;", statement.SQL())
+
+	_, err = safeBuild(del.Where(users.C("id").Eq(1)))
+	assert.Nil(t, err)
+	_, err = safeBuild(upd.Where(users.C("id").Eq(1)))
+	assert.Nil(t, err)
+
+	// not safe
+	_, err = BuildStmt(NewCompilerContext(NewDialect("postgres")), del)
+	assert.Nil(t, err)
+}
+
+func TestCheckBinds(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("name", Varchar()))
+	checkedBuild := func(dialect Dialect, clause Clause) (*Stmt, error) {
+		context := NewCompilerContext(dialect)
+		context.CheckBinds = true
+		return BuildStmt(context, clause)
+	}
+
+	sel := Select(users.C("id"), Count(users.C("name"))).
+		From(users).
+		Where(users.C("id").In(1, 2, 3), users.C("name").Eq("it's ?")).
+		GroupBy(users.C("id")).
+		Having(Count(users.C("name")), ">", 1)
+
+	for _, dialect := range []string{"sqlite3", "mysql", "postgres"} {
+		statement, err := checkedBuild(NewDialect(dialect), sel)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, len(statement.Bindings()))
+		assert.Equal(t, 5, countPlaceholders(statement.SQL()))
+	}
+
+	// a placeholder without bind
+	_, err := checkedBuild(NewDialect("postgres"), Select(users.C("id")).From(users).Where(SQLText("id = $1")))
+	assert.EqualError(t, err, "The SQL has 1 placeholders for 0 binds")
+
+	// quoted question marks are not placeholders
+	assert.Equal(t, 1, countPlaceholders("SELECT '?', \"?\", `$1` WHERE a = ?"))
+	assert.Equal(t, 2, countPlaceholders("SELECT $1, $12, $ WHERE 1"))
+}
+
+func TestNamedArgs(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("name", Varchar()))
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.Placeholder = NamedArgPlaceholder
+
+	statement, err := BuildStmt(context, Select(users.C("id")).
+		From(users).
+		Where(users.C("name").Eq("Jack"), users.C("id").Gt(10)))
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE (name = @arg1 AND id > @arg2);", statement.SQL())
+	assert.Equal(t, map[string]interface{}{"arg1": "Jack", "arg2": 10}, statement.NamedArgs())
+}
+
+func TestNamedBindings(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("status", Varchar()),
+		Column("age", Int()),
+	)
+
+	sel := Select(users.C("id")).
+		From(users).
+		Where(
+			users.C("status").In("active", "pending"),
+			users.C("age").Between(18, 65),
+			Eq(SQLText("LOWER(email)"), "joe@example.com"),
+		)
+	statement := sel.Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM users\n"+
+		"WHERE (status IN ($1, $2) AND age BETWEEN $3 AND $4 AND LOWER(email) = $5);", statement.SQL())
+	assert.Equal(t, []interface{}{"active", "pending", 18, 65, "joe@example.com"}, statement.Bindings())
+	assert.Equal(t, map[string]interface{}{
+		"status":   "active",
+		"status_2": "pending",
+		"age":      18,
+		"age_2":    65,
+		"arg5":     "joe@example.com",
+	}, statement.NamedBindings())
+
+	ins := Insert(users).Values(map[string]interface{}{"email": "joe@example.com", "age": 30}).Build(NewDialect("mysql"))
+	assert.Equal(t, "INSERT INTO users(age, email)\nVALUES(?, ?);", ins.SQL())
+	assert.Equal(t, map[string]interface{}{"age": 30, "email": "joe@example.com"}, ins.NamedBindings())
+}
+
+func TestValidate(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("name", Varchar()),
+	)
+	mysql := NewDialect("mysql")
+
+	assert.Empty(t, Select(users.C("name")).From(users).Where(Eq(users.C("id"), 1)).Validate(mysql))
+
+	sel := Select(users.C("name")).
+		OrderBy(Desc(users.C("name")).NullsLast()).
+		Limit(-1, 10)
+	assert.Equal(t, []error{
+		errors.New("Select of column users.name has no FROM clause"),
+		errors.New("NULLS LAST is not supported by mysql"),
+		errors.New("Invalid negative offset: -1"),
+	}, sel.Validate(mysql))
+
+	assert.Len(t, Delete(users).Validate(mysql), 1)
+	assert.Empty(t, Delete(users).Unsafe().Validate(mysql))
+}
+
+func TestInterpolate(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("name", Varchar()),
+		Column("score", Float()),
+		Column("active", Boolean()),
+		Column("deleted_at", Timestamp()),
+	)
+	sel := Select(users.C("id")).
+		From(users).
+		Where(
+			Eq(users.C("name"), "O'Reilly"),
+			Gt(users.C("score"), 2.5),
+			Eq(users.C("active"), true),
+			Eq(users.C("deleted_at"), nil),
+			Eq(users.C("id"), 7),
+		)
+
+	sql, err := sel.Build(NewDialect("postgres")).Interpolate()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\n"+
+		"WHERE (name = 'O''Reilly' AND score > 2.5 AND active = TRUE AND deleted_at = NULL AND id = 7);", sql)
+
+	sql, err = sel.Build(NewDialect("mysql")).Interpolate()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id\nFROM users\n"+
+		"WHERE (name = 'O''Reilly' AND score > 2.5 AND active = 1 AND deleted_at = NULL AND id = 7);", sql)
+
+	// the placeholders in quoted strings are kept
+	statement := Statement()
+	statement.AddSQLClause("SELECT '?', name FROM users WHERE name = ? AND id = ?")
+	statement.AddBinding(`back\slash`)
+	_, err = statement.Interpolate()
+	assert.EqualError(t, err, "The SQL has more placeholders than its 1 binds")
+	statement.AddBinding(3)
+	sql, err = statement.Interpolate()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT '?', name FROM users WHERE name = 'back\slash' AND id = 3;`, sql)
+}
+
+func TestEmbed(t *testing.T) {
+	users := Table("users", Column("id", Int()), Column("active", Boolean()), Column("country", Varchar()))
+	orders := Table("orders", Column("id", Int()), Column("user_id", Int()), Column("total", Int()))
+
+	active := Select(users.C("id")).
+		From(users).
+		Where(users.C("active").Eq(true), users.C("country").Eq("fr")).
+		Build(NewDialect("mysql"))
+	fragment, err := active.Embed()
+	assert.Nil(t, err)
+
+	statement := Select(orders.C("id")).
+		From(orders).
+		Where(orders.C("total").Gt(100), In(orders.C("user_id"), fragment)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM orders\n"+
+		"WHERE (total > $1 AND user_id IN (SELECT id\nFROM users\nWHERE (active = $2 AND country = $3)));", statement.SQL())
+	assert.Equal(t, []interface{}{100, true, "fr"}, statement.Bindings())
+
+	// a reused '$n' placeholder is bound at each of its positions
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.DedupBinds = true
+	same, err := BuildStmt(context, Select(users.C("id")).From(users).Where(Or(users.C("id").Eq(1), users.C("country").Eq(1))))
+	assert.Nil(t, err)
+	fragment, err = same.Embed()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SELECT id\nFROM users\nWHERE (id = ", " OR country = ", ")"}, fragment.Parts)
+	assert.Equal(t, []interface{}{1, 1}, fragment.Binds)
+
+	// the '?' operators of a postgres statement are not placeholders
+	tagged, err := BuildStmt(NewCompilerContext(NewDialect("postgres")),
+		Select(users.C("id")).From(users).Where(SQLText("tags ? 'vip'"), users.C("country").Eq("fr")))
+	assert.Nil(t, err)
+	fragment, err = tagged.Embed()
+	assert.Nil(t, err)
+	statement = Select(orders.C("id")).
+		From(orders).
+		Where(orders.C("total").Gt(100), In(orders.C("user_id"), fragment)).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM orders\n"+
+		"WHERE (total > $1 AND user_id IN (SELECT id\nFROM users\nWHERE (tags ? 'vip' AND country = $2)));", statement.SQL())
+	assert.Equal(t, []interface{}{100, "fr"}, statement.Bindings())
+
+	broken := Statement()
+	broken.AddSQLClause("SELECT id FROM users WHERE id = ? OR id = ?")
+	broken.AddBinding(1)
+	_, err = broken.Embed()
+	assert.EqualError(t, err, "The SQL has more placeholders than its 1 binds")
+	broken.driver = "postgres"
+	broken.clauses = []string{"SELECT id FROM users WHERE id = $2"}
+	_, err = broken.Embed()
+	assert.EqualError(t, err, "The SQL placeholder $2 has no bind")
+
+	// a hand-built embed must have a part around each bind
+	_, _, err = Select(orders.C("id")).
+		From(orders).
+		Where(In(orders.C("user_id"), EmbedClause{Parts: []string{"SELECT id FROM users WHERE id = "}, Binds: []interface{}{1}})).
+		ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "The embedded statement has 1 parts, 2 expected for its 1 binds")
+	_, _, err = Select(orders.C("id")).From(orders).Where(In(orders.C("user_id"), EmbedClause{})).ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "The embedded statement has 0 parts, 1 expected for its 0 binds")
+}
+
+func TestMaxBinds(t *testing.T) {
+	users := Table("users", Column("id", Int()))
+	sel := Select(users.C("id")).From(users).Where(users.C("id").In(1, 2, 3, 4))
+
+	context := NewCompilerContext(NewDialect("postgres"))
+	context.MaxBinds = 3
+	_, err := BuildStmt(context, sel)
+	assert.EqualError(t, err, "The statement has 4 binds, more than the maximum of 3")
+
+	context = NewCompilerContext(NewDialect("postgres"))
+	context.MaxBinds = 4
+	statement, err := BuildStmt(context, sel)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(statement.Bindings()))
+}
@@ -2,6 +2,7 @@ package qb
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -80,6 +81,11 @@ type TableElem struct {
 	ForeignKeyConstraints ForeignKeyConstraints
 	UniqueKeyConstraint   UniqueKeyConstraint
 	Indices               []IndexElem
+	only                  bool
+	strict                bool
+	storage               map[string]string
+	tablespace            string
+	comment               string
 }
 
 // DefaultName returns the name of the table
@@ -105,12 +111,65 @@ func (t TableElem) ColumnList() []ColumnElem {
 	return cols
 }
 
+// Only returns a copy of the table that, when used in a FROM clause or as
+// the target of an UPDATE or DELETE, excludes the inheriting tables.
+// It is only relevant on postgres, other dialects ignore it
+func (t TableElem) Only() TableElem {
+	t.only = true
+	return t
+}
+
+// Strict returns a copy of the table whose C() function panics if the
+// column name is not one of the table columns, to catch the typos when
+// building the queries instead of at the database
+func (t TableElem) Strict() TableElem {
+	t.strict = true
+	return t
+}
+
+// TableSample returns a sample of the table to be used in a FROM clause
+// TableSample("SYSTEM", 10) samples 10% of the table
+func (t TableElem) TableSample(method string, percentage float64) TableSampleClause {
+	return TableSample(t, method, percentage)
+}
+
 // Index appends an IndexElem to current table without giving table name
 func (t TableElem) Index(cols ...string) TableElem {
 	t.Indices = append(t.Indices, Index(t.Name, cols...))
 	return t
 }
 
+// With returns a copy of the table with the given storage parameters, that
+// are added to its CREATE TABLE as WITH (fillfactor=70)
+// NOTE: it is postgres specific, other dialects ignore it
+func (t TableElem) With(params map[string]string) TableElem {
+	storage := map[string]string{}
+	for k, v := range t.storage {
+		storage[k] = v
+	}
+	for k, v := range params {
+		storage[k] = v
+	}
+	t.storage = storage
+	return t
+}
+
+// Tablespace returns a copy of the table that is created in the given
+// tablespace
+// NOTE: it is postgres specific, other dialects ignore it
+func (t TableElem) Tablespace(name string) TableElem {
+	t.tablespace = name
+	return t
+}
+
+// Comment returns a copy of the table with the given comment. mysql renders
+// it as a table option, postgres in a COMMENT ON TABLE statement following
+// the CREATE TABLE, sqlite ignores it
+func (t TableElem) Comment(comment string) TableElem {
+	t.comment = comment
+	return t
+}
+
 // Create generates create table syntax and returns it as a query struct
 func (t TableElem) Create(dialect Dialect) string {
 	statement := Statement()
@@ -135,7 +194,28 @@ func (t TableElem) Create(dialect Dialect) string {
 
 	statement.AddSQLClause(strings.Join(colClauses, ",\n"))
 
-	statement.AddSQLClause(")")
+	options := ""
+	if dialect.SupportsTableStorage() {
+		if len(t.storage) > 0 {
+			keys := []string{}
+			for k := range t.storage {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			params := []string{}
+			for _, k := range keys {
+				params = append(params, fmt.Sprintf("%s=%s", k, t.storage[k]))
+			}
+			options += fmt.Sprintf(" WITH (%s)", strings.Join(params, ", "))
+		}
+		if t.tablespace != "" {
+			options += " TABLESPACE " + dialect.Escape(t.tablespace)
+		}
+	}
+	if dialect.SupportsInlineComments() && t.comment != "" {
+		options += " COMMENT=" + dialect.QuoteLiteral(t.comment)
+	}
+	statement.AddSQLClause(")" + options)
 
 	ddl := statement.SQL()
 
@@ -148,6 +228,21 @@ func (t TableElem) Create(dialect Dialect) string {
 	sqls := []string{ddl}
 	sqls = append(sqls, indexSqls...)
 
+	if dialect.SupportsCommentOn() {
+		if t.comment != "" {
+			sqls = append(sqls, fmt.Sprintf("COMMENT ON TABLE %s IS %s;",
+				dialect.Escape(t.Name), dialect.QuoteLiteral(t.comment)))
+		}
+		// sorted, the columns being a map
+		for _, name := range sortedColumnNames(t.Columns) {
+			col := t.Columns[name]
+			if col.Options.Comment != "" {
+				sqls = append(sqls, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;",
+					dialect.Escape(t.Name), dialect.Escape(col.Name), dialect.QuoteLiteral(col.Options.Comment)))
+			}
+		}
+	}
+
 	return strings.Join(sqls, "\n")
 }
 
@@ -177,8 +272,17 @@ func (t TableElem) Drop(dialect Dialect) string {
 }
 
 // C returns the column name given col
+// The returned column always carries the table name, even if it was added
+// to Columns directly, so it gets qualified when used outside of its table.
+// On a Strict() table, it panics if the column does not exist.
 func (t TableElem) C(name string) ColumnElem {
-	return t.Columns[name]
+	col, ok := t.Columns[name]
+	if ok {
+		col.Table = t.Name
+	} else if t.strict {
+		panic(fmt.Sprintf("Table %s has no column '%s'", t.Name, name))
+	}
+	return col
 }
 
 // query starters
@@ -212,3 +316,13 @@ func (t TableElem) Select(clauses ...Clause) SelectStmt {
 func (t TableElem) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitTable(context, t)
 }
+
+// sortedColumnNames returns the names of the columns in alphabetical order
+func sortedColumnNames(columns map[string]ColumnElem) []string {
+	names := []string{}
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -189,6 +189,113 @@ func (suite *TableTestSuite) TestTableStarters() {
 	assert.Equal(suite.T(), []interface{}{"5a73ef89-cf0a-4c51-ab8c-cc273ebb3a55"}, sel.Bindings())
 }
 
+func (suite *TableTestSuite) TestTableOnly() {
+	postgres := NewDialect("postgres")
+	sqlite := NewDialect("sqlite3")
+
+	cities := Table(
+		"cities",
+		Column("name", Varchar()),
+		Column("population", Int()),
+	)
+	only := cities.Only()
+
+	sel := Select(only.C("name")).From(only).Where(Gt(only.C("population"), 1000))
+	assert.Equal(suite.T(), "SELECT name\nFROM ONLY cities\nWHERE population > $1;", sel.Build(postgres).SQL())
+	assert.Equal(suite.T(), "SELECT name\nFROM cities\nWHERE population > ?;", sel.Build(sqlite).SQL())
+
+	del := Delete(only).Where(Eq(only.C("name"), "Paris"))
+	assert.Equal(suite.T(), "DELETE FROM ONLY cities\nWHERE cities.name = $1;", del.Build(postgres).SQL())
+
+	upd := Update(only).Values(map[string]interface{}{"population": 0})
+	assert.Equal(suite.T(), "UPDATE ONLY cities\nSET population = $1;", upd.Build(postgres).SQL())
+
+	assert.Equal(suite.T(), "SELECT name\nFROM cities;", Select(cities.C("name")).From(cities).Build(postgres).SQL())
+}
+
+func (suite *TableTestSuite) TestTableStrict() {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	).Strict()
+
+	assert.Equal(suite.T(), ColumnElem{Name: "email", Type: Varchar(), Table: "users"}, users.C("email"))
+	sel := Select(users.C("email")).From(users).Where(Eq(users.C("id"), 1)).Build(NewDialect("postgres"))
+	assert.Equal(suite.T(), "SELECT email\nFROM users\nWHERE id = $1;", sel.SQL())
+
+	assert.PanicsWithValue(suite.T(), "Table users has no column 'emial'", func() {
+		users.C("emial")
+	})
+}
+
+func (suite *TableTestSuite) TestTableStorage() {
+	base := Table(
+		"events",
+		Column("id", Int()),
+	).With(map[string]string{"fillfactor": "70"})
+	events := base.With(map[string]string{"autovacuum_enabled": "false"}).Tablespace("fast")
+
+	assert.Equal(suite.T(), "CREATE TABLE events (\n\tid INT\n) WITH (autovacuum_enabled=false, fillfactor=70) TABLESPACE fast;", events.Create(NewDialect("postgres")))
+	assert.Equal(suite.T(), "CREATE TABLE events (\n\tid INT\n);", events.Create(NewDialect("mysql")))
+
+	// With returns a copy
+	assert.Equal(suite.T(), "CREATE TABLE events (\n\tid INT\n) WITH (fillfactor=70);", base.Create(NewDialect("postgres")))
+}
+
+func (suite *TableTestSuite) TestTableColumnMetadata() {
+	nullable := true
+	now := "CURRENT_TIMESTAMP"
+	accounts := Table(
+		"accounts",
+		Column("id", Int()).NotNull(),
+		Column("name", Varchar().Size(40)).NotNull().Default("anonymous").Comment("Display name"),
+		Column("created_at", Timestamp()).Comment("It's set on insert"),
+		// as filled from an introspected schema
+		ColumnElem{
+			Name:    "closed_at",
+			Type:    Timestamp(),
+			Options: ColumnOptions{Nullable: &nullable, Default: &now},
+		},
+	)
+
+	name := accounts.C("name")
+	assert.False(suite.T(), *name.Options.Nullable)
+	assert.Equal(suite.T(), "'anonymous'", *name.Options.Default)
+	assert.Equal(suite.T(), "Display name", name.Options.Comment)
+
+	ddl := accounts.Create(NewDialect("postgres"))
+	assert.Contains(suite.T(), ddl, "\tid INT NOT NULL")
+	assert.Contains(suite.T(), ddl, "\tname VARCHAR(40) NOT NULL DEFAULT 'anonymous'")
+	assert.Contains(suite.T(), ddl, "\tcreated_at TIMESTAMP")
+	assert.Contains(suite.T(), ddl, "\tclosed_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP")
+	assert.Contains(suite.T(), ddl, ");\n"+
+		"COMMENT ON COLUMN accounts.created_at IS 'It''s set on insert';\n"+
+		"COMMENT ON COLUMN accounts.name IS 'Display name';")
+
+	ddl = accounts.Create(NewDialect("mysql"))
+	assert.Contains(suite.T(), ddl, "\tname VARCHAR(40) NOT NULL DEFAULT 'anonymous' COMMENT 'Display name'")
+	assert.Contains(suite.T(), ddl, "\tcreated_at TIMESTAMP COMMENT 'It''s set on insert'")
+	assert.NotContains(suite.T(), ddl, "COMMENT ON")
+
+	assert.NotContains(suite.T(), accounts.Create(NewDialect("sqlite3")), "COMMENT")
+}
+
+func (suite *TableTestSuite) TestTableComment() {
+	events := Table(
+		"events",
+		Column("id", Int()).Comment("Event id"),
+	).Comment("Audit events")
+
+	assert.Equal(suite.T(), "CREATE TABLE events (\n\tid INT\n);\n"+
+		"COMMENT ON TABLE events IS 'Audit events';\n"+
+		"COMMENT ON COLUMN events.id IS 'Event id';",
+		events.Create(NewDialect("postgres")))
+	assert.Equal(suite.T(), "CREATE TABLE events (\n\tid INT COMMENT 'Event id'\n) COMMENT='Audit events';",
+		events.Create(NewDialect("mysql")))
+	assert.Equal(suite.T(), "CREATE TABLE events (\n\tid INT\n);", events.Create(NewDialect("sqlite3")))
+}
+
 func TestTableTestSuite(t *testing.T) {
 	suite.Run(t, new(TableTestSuite))
 }
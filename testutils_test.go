@@ -19,8 +19,7 @@ func asSQL(clause Clause, dialect Dialect) string {
 }
 
 func asSQLBinds(clause Clause, dialect Dialect) (string, []interface{}) {
-	ctx := NewCompilerContext(dialect)
-	return clause.Accept(ctx), ctx.Binds
+	return Compile(clause, dialect)
 }
 
 type TestingLogWriter struct {
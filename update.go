@@ -8,7 +8,7 @@ func Update(table TableElem) UpdateStmt {
 	return UpdateStmt{
 		table:     table,
 		values:    map[string]interface{}{},
-		returning: []ColumnElem{},
+		returning: []Clause{},
 	}
 }
 
@@ -16,8 +16,19 @@ func Update(table TableElem) UpdateStmt {
 type UpdateStmt struct {
 	table     TableElem
 	values    map[string]interface{}
-	returning []ColumnElem
+	returning []Clause
+	with      WithClause
+	from      Selectable
 	where     *WhereClause
+	unsafe    bool
+	orderBy   *OrderByClause
+	count     *int
+}
+
+// With appends common table expressions to the statement
+func (s UpdateStmt) With(ctes ...CTEClause) UpdateStmt {
+	s.with.CTEs = append(append([]CTEClause{}, s.with.CTEs...), ctes...)
+	return s
 }
 
 // Accept implements Clause.Accept
@@ -26,34 +37,92 @@ func (s UpdateStmt) Accept(context *CompilerContext) string {
 }
 
 // Build generates a statement out of UpdateStmt object
+// It panics if the statement cannot be compiled
 func (s UpdateStmt) Build(dialect Dialect) *Stmt {
-	context := NewCompilerContext(dialect)
-	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
-	statement.AddBinding(context.Binds...)
+	return mustBuildStmt(dialect, s)
+}
+
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s UpdateStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
 
-	return statement
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s UpdateStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s UpdateStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
+
+// String compiles the statement with the default dialect, for inspection
+func (s UpdateStmt) String() string {
+	return stmtString(s)
 }
 
 // Values accepts map[string]interface{} and forms the values map of insert statement
+// A value can be a Clause, for example a Subquery(), in which case it is
+// compiled in place of a bind
 func (s UpdateStmt) Values(values map[string]interface{}) UpdateStmt {
+	merged := map[string]interface{}{}
+	for k, v := range s.values {
+		merged[k] = v
+	}
 	for k, v := range values {
-		s.values[s.table.C(k).Name] = v
+		merged[s.table.C(k).Name] = v
 	}
+	s.values = merged
+	return s
+}
+
+// OrderBy sets the order in which the rows are updated, to be used with
+// LimitCount
+// NOTE: only mysql supports it
+func (s UpdateStmt) OrderBy(columns ...Clause) UpdateStmt {
+	s.orderBy = &OrderByClause{columns, "ASC"}
+	return s
+}
+
+// LimitCount bounds the number of updated rows
+// NOTE: only mysql supports it
+func (s UpdateStmt) LimitCount(count int) UpdateStmt {
+	s.count = &count
 	return s
 }
 
-// Returning accepts the column names as strings and forms the returning array of insert statement
+// Returning accepts the columns or expressions, possibly aliased with As(),
+// and forms the returning array of the statement
 // NOTE: Please use it in only postgres dialect, otherwise it'll crash
-func (s UpdateStmt) Returning(cols ...ColumnElem) UpdateStmt {
-	for _, c := range cols {
-		s.returning = append(s.returning, c)
-	}
+func (s UpdateStmt) Returning(cols ...Clause) UpdateStmt {
+	s.returning = append(append([]Clause{}, s.returning...), cols...)
+	return s
+}
+
+// From sets a selectable the update can read from, to be joined in the
+// where clause: UPDATE ... SET ... FROM ... WHERE ...
+// NOTE: mysql does not support it
+func (s UpdateStmt) From(selectable Selectable) UpdateStmt {
+	s.from = selectable
+	return s
+}
+
+// Unsafe marks the statement as updating all the rows on purpose when it has
+// no where clause, so it is accepted in safe mode
+func (s UpdateStmt) Unsafe() UpdateStmt {
+	s.unsafe = true
 	return s
 }
 
 // Where adds a where clause to update statement and returns the update statement
-func (s UpdateStmt) Where(clause Clause) UpdateStmt {
-	s.where = &WhereClause{clause}
+// Several clauses are combined with AND
+func (s UpdateStmt) Where(clauses ...Clause) UpdateStmt {
+	where := Where(clauses...)
+	s.where = &where
 	return s
 }
@@ -47,3 +47,143 @@ func TestUpdate(t *testing.T) {
 	assert.Equal(t, "UPDATE \"users\"\nSET \"email\" = $1\nWHERE \"email\" = $2\nRETURNING \"id\", \"email\";", statement.SQL())
 	assert.Equal(t, []interface{}{"robert@de.niro", "al@pacino"}, statement.Bindings())
 }
+
+func TestUpdateSubquery(t *testing.T) {
+	orders := Table(
+		"orders",
+		Column("id", BigInt()),
+		Column("total", Int()),
+		PrimaryKey("id"),
+	)
+	items := Table(
+		"items",
+		Column("order_id", BigInt()),
+		Column("price", Int()),
+		Column("canceled", Boolean()),
+	)
+
+	statement := Update(orders).
+		Values(map[string]interface{}{
+			"total": Subquery(
+				Select(Sum(items.C("price"))).
+					From(items).
+					Where(
+						Eq(items.C("order_id"), orders.C("id")),
+						Eq(items.C("canceled"), false),
+					),
+			),
+		}).
+		Where(Eq(orders.C("id"), 12)).
+		Build(NewDialect("postgres"))
+
+	assert.Equal(t, `UPDATE orders
+SET total = (SELECT SUM(items.price)
+FROM items
+WHERE (items.order_id = orders.id AND items.canceled = $1))
+WHERE id = $2;`, statement.SQL())
+	assert.Equal(t, []interface{}{false, 12}, statement.Bindings())
+}
+
+func TestUpdateWhereClauses(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", BigInt()).NotNull(),
+		Column("email", Varchar()).NotNull(),
+		Column("active", Boolean()),
+	)
+	upd := Update(users).Values(map[string]interface{}{"active": false})
+
+	statement := upd.Where(users.C("id").Eq(1)).Build(NewDialect("sqlite3"))
+	assert.Equal(t, "UPDATE users\nSET active = ?\nWHERE id = ?;", statement.SQL())
+	assert.Equal(t, []interface{}{false, 1}, statement.Bindings())
+
+	statement = upd.Where(users.C("id").Gt(1), users.C("email").Eq("al@pacino.com")).Build(NewDialect("sqlite3"))
+	assert.Equal(t, "UPDATE users\nSET active = ?\nWHERE (id > ? AND email = ?);", statement.SQL())
+	assert.Equal(t, []interface{}{false, 1, "al@pacino.com"}, statement.Bindings())
+}
+
+func TestUpdateFrom(t *testing.T) {
+	accounts := Table(
+		"accounts",
+		Column("id", BigInt()),
+		Column("balance", Int()),
+		Column("status", Varchar()),
+		Column("updated_by", Varchar()),
+	)
+	transfers := Table(
+		"transfers",
+		Column("account_id", BigInt()),
+		Column("amount", Int()),
+		Column("day", Varchar()),
+	)
+
+	upd := Update(accounts).
+		Values(map[string]interface{}{
+			"updated_by": "batch",
+			"balance":    BinaryExpression(accounts.C("balance"), "+", transfers.C("amount")),
+			"status":     "credited",
+		}).
+		From(transfers).
+		Where(
+			accounts.C("id").Eq(transfers.C("account_id")),
+			transfers.C("day").Eq("2016-01-01"),
+		)
+
+	for i := 0; i < 10; i++ {
+		statement := upd.Build(NewDialect("postgres"))
+		assert.Equal(t, "UPDATE accounts\nSET balance = balance + transfers.amount, status = $1, updated_by = $2\nFROM transfers\nWHERE (accounts.id = transfers.account_id AND transfers.day = $3);", statement.SQL())
+		assert.Equal(t, []interface{}{"credited", "batch", "2016-01-01"}, statement.Bindings())
+	}
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("mysql")), upd)
+	assert.EqualError(t, err, "UPDATE ... FROM is not supported by mysql")
+
+	// both tables are in scope, the returned columns are qualified
+	statement := upd.Returning(accounts.C("id"), accounts.C("balance"), As(transfers.C("amount"), "credit")).Build(NewDialect("postgres"))
+	assert.Equal(t, "UPDATE accounts\nSET balance = balance + transfers.amount, status = $1, updated_by = $2\nFROM transfers\nWHERE (accounts.id = transfers.account_id AND transfers.day = $3)\nRETURNING accounts.id, accounts.balance, transfers.amount AS credit;", statement.SQL())
+}
+
+func TestUpdateFromValues(t *testing.T) {
+	items := Table("items", Column("id", BigInt()), Column("v", Varchar()))
+	data := Alias("d", Values([]string{"id", "v"}, []interface{}{1, "a"}, []interface{}{2, "b"}))
+
+	statement := Update(items).
+		Values(map[string]interface{}{"v": data.C("v")}).
+		From(data).
+		Where(items.C("id").Eq(data.C("id"))).
+		Build(NewDialect("postgres"))
+
+	assert.Equal(t, "UPDATE items\nSET v = d.v\nFROM (VALUES ($1, $2), ($3, $4)) AS d(id, v)\nWHERE items.id = d.id;", statement.SQL())
+	assert.Equal(t, []interface{}{1, "a", 2, "b"}, statement.Bindings())
+
+	mysql := NewDialect("mysql")
+	mysql.SetEscaping(true)
+	statement = Select(data.C("id")).From(data).Build(mysql)
+	assert.Equal(t, "SELECT `id`\nFROM (VALUES ROW(?, ?), ROW(?, ?)) AS `d`(`id`, `v`);", statement.SQL())
+
+	_, _, err := Update(items).
+		Values(map[string]interface{}{"v": "c"}).
+		From(Alias("d", Values([]string{"id", "v"}, []interface{}{1}))).
+		Where(items.C("id").Eq(1)).
+		ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "VALUES row 1 has 1 values, expected 2")
+
+	_, _, err = Select(data.C("id")).From(data).ToSQL(NewDialect("sqlite3"))
+	assert.EqualError(t, err, "VALUES list in FROM is not supported by sqlite")
+}
+
+func TestUpdateReturningExpressions(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	statement := Update(users).
+		Values(map[string]interface{}{"email": "robert@de.niro"}).
+		Where(Eq(users.C("id"), 5)).
+		Returning(users.C("id"), As(SQLText("now()"), "updated_at")).
+		Build(NewDialect("postgres"))
+	assert.Equal(t, "UPDATE users\nSET email = $1\nWHERE id = $2\nRETURNING id, now() AS updated_at;", statement.SQL())
+	assert.Equal(t, []interface{}{"robert@de.niro", 5}, statement.Bindings())
+}
@@ -5,45 +5,159 @@ func Upsert(table TableElem) UpsertStmt {
 	return UpsertStmt{
 		table:     table,
 		values:    map[string]interface{}{},
-		returning: []ColumnElem{},
+		returning: []Clause{},
 	}
 }
 
 // UpsertStmt is the base struct for any insert ... on conflict/duplicate key ... update ... statements
 type UpsertStmt struct {
-	table     TableElem
-	values    map[string]interface{}
-	returning []ColumnElem
+	table      TableElem
+	values     map[string]interface{}
+	set        map[string]interface{}
+	where      *WhereClause
+	returning  []Clause
+	conflict   []string
+	exprs      []Clause
+	constraint string
+	doNothing  bool
 }
 
 // Values accepts map[string]interface{} and forms the values map of insert statement
 func (s UpsertStmt) Values(values map[string]interface{}) UpsertStmt {
+	merged := map[string]interface{}{}
+	for k, v := range s.values {
+		merged[k] = v
+	}
 	for k, v := range values {
-		s.values[k] = v
+		merged[k] = v
 	}
+	s.values = merged
 	return s
 }
 
-// Returning accepts the column names as strings and forms the returning array of insert statement
-// NOTE: Please use it in only postgres dialect, otherwise it'll crash
-func (s UpsertStmt) Returning(cols ...ColumnElem) UpsertStmt {
-	for _, c := range cols {
-		s.returning = append(s.returning, c)
+// Set accepts map[string]interface{} and forms the values map of the update
+// that runs if the row already exists. The values can be clauses, like
+// Excluded(). If Set is not called, the update sets the inserted values
+func (s UpsertStmt) Set(values map[string]interface{}) UpsertStmt {
+	set := map[string]interface{}{}
+	for k, v := range s.set {
+		set[k] = v
 	}
+	for k, v := range values {
+		set[k] = v
+	}
+	s.set = set
+	return s
+}
+
+// OnConflict sets the columns of the unique constraint whose conflicts
+// trigger the update, the primary key columns being the default
+// NOTE: mysql does not support it
+func (s UpsertStmt) OnConflict(columns ...string) UpsertStmt {
+	s.conflict = columns
+	return s
+}
+
+// OnConflictExpr appends expressions to the conflict target, to match an
+// expression index: ON CONFLICT ((lower(email)))
+// NOTE: mysql does not support it
+func (s UpsertStmt) OnConflictExpr(exprs ...Clause) UpsertStmt {
+	s.exprs = append(append([]Clause{}, s.exprs...), exprs...)
 	return s
 }
 
+// OnConstraint sets the name of the unique constraint whose conflicts
+// trigger the update, instead of its columns: ON CONFLICT ON CONSTRAINT name
+// NOTE: it is postgres specific
+func (s UpsertStmt) OnConstraint(name string) UpsertStmt {
+	s.constraint = name
+	return s
+}
+
+// DoNothing makes the statement skip the rows that already exist instead of
+// updating them
+// NOTE: mysql does not support it
+func (s UpsertStmt) DoNothing() UpsertStmt {
+	s.doNothing = true
+	return s
+}
+
+// Where sets a condition on the update that runs if the row already exists,
+// so the row is updated only if it matches
+// Several clauses are combined with AND
+// NOTE: mysql does not support it
+func (s UpsertStmt) Where(clauses ...Clause) UpsertStmt {
+	where := Where(clauses...)
+	s.where = &where
+	return s
+}
+
+// updateValues returns the values of the update that runs if the row
+// already exists
+func (s UpsertStmt) updateValues() map[string]interface{} {
+	if len(s.set) > 0 {
+		return s.set
+	}
+	return s.values
+}
+
+// Returning accepts the columns or expressions, possibly aliased with As(),
+// and forms the returning array of the statement
+// NOTE: Please use it in only postgres dialect, otherwise it'll crash
+func (s UpsertStmt) Returning(cols ...Clause) UpsertStmt {
+	s.returning = append(append([]Clause{}, s.returning...), cols...)
+	return s
+}
+
+// Excluded returns an ExcludedClause, to be used in the Set values of an
+// upsert statement
+func Excluded(column ColumnElem) ExcludedClause {
+	return ExcludedClause{Column: column}
+}
+
+// ExcludedClause is the value of a column in the row proposed for insertion
+// by an upsert, like EXCLUDED.col on postgres and sqlite, or VALUES(col) on
+// mysql
+type ExcludedClause struct {
+	Column ColumnElem
+}
+
+// Accept calls the compiler VisitExcluded function
+func (c ExcludedClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitExcluded(context, c)
+}
+
 // Accept calls the compiler VisitUpsert function
 func (s UpsertStmt) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitUpsert(context, s)
 }
 
 // Build generates a statement out of UpdateStmt object
+// It panics if the statement cannot be compiled
 func (s UpsertStmt) Build(dialect Dialect) *Stmt {
-	context := NewCompilerContext(dialect)
-	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
-	statement.AddBinding(context.Binds...)
+	return mustBuildStmt(dialect, s)
+}
+
+// ToSQL compiles the statement with the given dialect and returns its SQL
+// and bindings. Contrary to Build, it returns the compilation errors
+func (s UpsertStmt) ToSQL(dialect Dialect) (string, []interface{}, error) {
+	return toSQL(dialect, s)
+}
+
+// Validate compiles the statement with the given dialect, in strict and safe
+// mode, and returns all the issues found instead of the SQL
+func (s UpsertStmt) Validate(dialect Dialect) []error {
+	return validate(dialect, s)
+}
+
+// CanonicalSQL compiles the statement with the given dialect and returns its
+// SQL with normalized whitespaces, lowercased outside of the quotes. It is
+// meant as a stable cache or log key, not to be run
+func (s UpsertStmt) CanonicalSQL(dialect Dialect) (string, error) {
+	return canonicalSQL(dialect, s)
+}
 
-	return statement
+// String compiles the statement with the default dialect, for inspection
+func (s UpsertStmt) String() string {
+	return stmtString(s)
 }
@@ -86,3 +86,228 @@ func TestUpsert(t *testing.T) {
 	assert.Contains(t, statement.Bindings(), "al@pacino.com")
 	assert.Equal(t, 4, len(statement.Bindings()))
 }
+
+func TestUpsertExcluded(t *testing.T) {
+	counters := Table(
+		"counters",
+		Column("name", Varchar()),
+		Column("count", Int()),
+		PrimaryKey("name"),
+	)
+
+	ups := Upsert(counters).
+		Values(map[string]interface{}{"name": "hits", "count": 1}).
+		Set(map[string]interface{}{
+			"count": BinaryExpression(counters.C("count"), "+", Excluded(counters.C("count"))),
+		})
+
+	var statement *Stmt
+
+	statement = ups.Build(NewDialect("postgres"))
+	assert.Contains(t, statement.SQL(), "\nON CONFLICT (name) DO UPDATE SET count = counters.count + EXCLUDED.count;")
+	assert.Contains(t, statement.Bindings(), "hits")
+	assert.Contains(t, statement.Bindings(), 1)
+	assert.Equal(t, 2, len(statement.Bindings()))
+
+	statement = ups.Build(NewDialect("sqlite3"))
+	assert.Contains(t, statement.SQL(), "INSERT INTO counters(")
+	assert.Contains(t, statement.SQL(), "VALUES(?, ?)\nON CONFLICT (name) DO UPDATE SET count = counters.count + EXCLUDED.count;")
+	assert.Equal(t, 2, len(statement.Bindings()))
+
+	statement = ups.Build(NewDialect("mysql"))
+	assert.Contains(t, statement.SQL(), "VALUES(?, ?)\nON DUPLICATE KEY UPDATE count = counters.count + VALUES(count);")
+	assert.Equal(t, 2, len(statement.Bindings()))
+}
+
+func TestUpsertWhere(t *testing.T) {
+	counters := Table(
+		"counters",
+		Column("name", Varchar()),
+		Column("count", Int()),
+		PrimaryKey("name"),
+	)
+
+	ups := Upsert(counters).
+		Values(map[string]interface{}{"name": "hits"}).
+		Set(map[string]interface{}{"count": 0}).
+		Where(counters.C("count").Gt(100))
+
+	statement := ups.Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO counters(name)\nVALUES($1)\nON CONFLICT (name) DO UPDATE SET count = $2\nWHERE counters.count > $3;", statement.SQL())
+	assert.Equal(t, []interface{}{"hits", 0, 100}, statement.Bindings())
+
+	statement = ups.Build(NewDialect("sqlite3"))
+	assert.Equal(t, "INSERT INTO counters(name)\nVALUES(?)\nON CONFLICT (name) DO UPDATE SET count = ?\nWHERE counters.count > ?;", statement.SQL())
+	assert.Equal(t, []interface{}{"hits", 0, 100}, statement.Bindings())
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("mysql")), ups)
+	assert.EqualError(t, err, "Upsert WHERE is not supported by mysql")
+}
+
+func TestUpsertReturningExpression(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("created_at", Timestamp()),
+		PrimaryKey("id"),
+	)
+
+	statement := Upsert(users).
+		Values(map[string]interface{}{"id": 1}).
+		Set(map[string]interface{}{"email": "al@pacino.com"}).
+		Returning(
+			users.C("id"),
+			users.C("created_at"),
+			As(Eq(SQLText("xmax"), IntLiteral(0)), "inserted"),
+			As(Eq(users.C("email"), "al@pacino.com"), "same_email"),
+		).
+		Build(NewDialect("postgres"))
+
+	assert.Equal(t, "INSERT INTO users(id)\nVALUES($1)\nON CONFLICT (id) DO UPDATE SET email = $2\nRETURNING id, created_at, xmax = 0 AS inserted, email = $3 AS same_email;", statement.SQL())
+	assert.Equal(t, []interface{}{1, "al@pacino.com", "al@pacino.com"}, statement.Bindings())
+}
+
+func TestUpsertOnConflict(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("name", Varchar()),
+		Column("visits", Int()),
+		PrimaryKey("id"),
+	)
+
+	ups := Upsert(users).
+		Values(map[string]interface{}{"email": "joe@example.com", "name": "Joe", "visits": 1}).
+		OnConflict("email").
+		Set(map[string]interface{}{
+			"visits": Excluded(users.C("visits")),
+			"name":   Excluded(users.C("name")),
+		})
+
+	var statement *Stmt
+
+	statement = ups.Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO users(email, name, visits)\n"+
+		"VALUES($1, $2, $3)\n"+
+		"ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, visits = EXCLUDED.visits;", statement.SQL())
+	assert.Equal(t, []interface{}{"joe@example.com", "Joe", 1}, statement.Bindings())
+
+	statement = ups.Build(NewDialect("sqlite3"))
+	assert.Contains(t, statement.SQL(), "VALUES(?, ?, ?)\nON CONFLICT (email) DO UPDATE SET")
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("mysql")), ups)
+	assert.EqualError(t, err, "Upsert conflict columns are not supported by mysql")
+}
+
+func TestUpsertDoNothing(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		PrimaryKey("id"),
+	)
+
+	ups := Upsert(users).
+		Values(map[string]interface{}{"id": 1, "email": "joe@example.com"}).
+		DoNothing()
+
+	var statement *Stmt
+
+	statement = ups.Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO users(email, id)\n"+
+		"VALUES($1, $2)\n"+
+		"ON CONFLICT (id) DO NOTHING;", statement.SQL())
+	assert.Equal(t, []interface{}{"joe@example.com", 1}, statement.Bindings())
+
+	statement = ups.OnConflict("email").Build(NewDialect("sqlite3"))
+	assert.Equal(t, "INSERT INTO users(email, id)\n"+
+		"VALUES(?, ?)\n"+
+		"ON CONFLICT (email) DO NOTHING;", statement.SQL())
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("mysql")), ups)
+	assert.EqualError(t, err, "Upsert DO NOTHING is not supported by mysql")
+
+	_, err = BuildStmt(NewCompilerContext(NewDialect("postgres")),
+		ups.Set(map[string]interface{}{"email": "jack@example.com"}))
+	assert.EqualError(t, err, "Upsert DO NOTHING cannot have update values or condition")
+}
+
+func TestUpsertNoConflictTarget(t *testing.T) {
+	logs := Table("logs", Column("id", Int()), Column("message", Varchar()))
+	ups := Upsert(logs).Values(map[string]interface{}{"id": 1, "message": "hi"})
+
+	// without a target, DO NOTHING skips any conflict
+	statement := ups.DoNothing().Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO logs(id, message)\n"+
+		"VALUES($1, $2)\n"+
+		"ON CONFLICT DO NOTHING;", statement.SQL())
+	assert.Equal(t, []interface{}{1, "hi"}, statement.Bindings())
+
+	_, _, err := ups.DoNothing().ToSQL(NewDialect("sqlite3"))
+	assert.Nil(t, err)
+
+	_, _, err = ups.ToSQL(NewDialect("postgres"))
+	assert.EqualError(t, err, "Upsert DO UPDATE needs conflict columns, a constraint or a primary key on table logs")
+}
+
+func TestUpsertOnConstraint(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		PrimaryKey("id"),
+	)
+
+	ups := Upsert(users).
+		Values(map[string]interface{}{"id": 1, "email": "joe@example.com"}).
+		OnConstraint("users_email_key").
+		Set(map[string]interface{}{"email": Excluded(users.C("email"))})
+
+	statement := ups.Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO users(email, id)\n"+
+		"VALUES($1, $2)\n"+
+		"ON CONFLICT ON CONSTRAINT users_email_key DO UPDATE SET email = EXCLUDED.email;", statement.SQL())
+	assert.Equal(t, []interface{}{"joe@example.com", 1}, statement.Bindings())
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("sqlite3")), ups)
+	assert.EqualError(t, err, "Upsert ON CONSTRAINT is not supported by sqlite")
+	_, err = BuildStmt(NewCompilerContext(NewDialect("mysql")), ups)
+	assert.EqualError(t, err, "Upsert ON CONSTRAINT is not supported by mysql")
+	_, err = BuildStmt(NewCompilerContext(NewDialect("postgres")), ups.OnConflict("email"))
+	assert.EqualError(t, err, "Upsert cannot have both conflict columns and a constraint")
+}
+
+func TestUpsertOnConflictExpr(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("tenant", Int()),
+		Column("email", Varchar()),
+		PrimaryKey("id"),
+	)
+
+	ups := Upsert(users).
+		Values(map[string]interface{}{"email": "Joe@Example.com", "tenant": 3}).
+		OnConflict("tenant").
+		OnConflictExpr(SQLText("lower(email)")).
+		DoNothing()
+
+	statement := ups.Build(NewDialect("postgres"))
+	assert.Equal(t, "INSERT INTO users(email, tenant)\n"+
+		"VALUES($1, $2)\n"+
+		"ON CONFLICT (tenant, (lower(email))) DO NOTHING;", statement.SQL())
+
+	statement = Upsert(users).
+		Values(map[string]interface{}{"email": "Joe@Example.com"}).
+		OnConflictExpr(SQLText("lower(email)")).
+		Set(map[string]interface{}{"email": Excluded(users.C("email"))}).
+		Build(NewDialect("sqlite3"))
+	assert.Equal(t, "INSERT INTO users(email)\n"+
+		"VALUES(?)\n"+
+		"ON CONFLICT ((lower(email))) DO UPDATE SET email = EXCLUDED.email;", statement.SQL())
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("mysql")), ups)
+	assert.Contains(t, err.Error(), "Upsert conflict columns are not supported by mysql")
+}
@@ -34,3 +34,30 @@ func TestWhereOr(t *testing.T) {
 		asDefSQL(
 			Where(SQLText("X")).Or(SQLText("Y"), SQLText("Z"))))
 }
+
+func TestWhereTextBinds(t *testing.T) {
+	data := Table("data", Column("id", Int()), Column("data", Text()))
+
+	sel := Select(data.C("id")).
+		From(data).
+		Where(data.C("id").Gt(10), SQLTextBinds("jsonb_path_exists(data, ?)", "$.a"))
+
+	statement := sel.Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id\nFROM data\nWHERE (id > $1 AND jsonb_path_exists(data, $2));", statement.SQL())
+	assert.Equal(t, []interface{}{10, "$.a"}, statement.Bindings())
+
+	sql, binds := asSQLBinds(
+		Where(data.C("id").Gt(10)).
+			And(SQLTextBinds("jsonb_path_exists(data, ?)", "$.a")).
+			Or(SQLTextBinds("id BETWEEN ? AND ?", 1, 5)),
+		NewDialect("postgres"))
+	assert.Equal(t, "WHERE ((data.id > $1 AND jsonb_path_exists(data, $2)) OR id BETWEEN $3 AND $4)", sql)
+	assert.Equal(t, []interface{}{10, "$.a", 1, 5}, binds)
+
+	sql, binds = asDefSQLBinds(Where(data.C("id").Eq(3)).And(SQLTextBinds("data ->> ? = ?", "k", "v")))
+	assert.Equal(t, "WHERE (data.id = ? AND data ->> ? = ?)", sql)
+	assert.Equal(t, []interface{}{3, "k", "v"}, binds)
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("default")), Select(SQLTextBinds("?, ?", 1)))
+	assert.EqualError(t, err, "SQL text has 2 placeholders for 1 binds: ?, ?")
+}
@@ -0,0 +1,130 @@
+package qb
+
+import (
+	"fmt"
+)
+
+// The window frame bounds
+const (
+	UnboundedPreceding = "UNBOUNDED PRECEDING"
+	CurrentRow         = "CURRENT ROW"
+	UnboundedFollowing = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding returns the "<n> PRECEDING" window frame bound
+func Preceding(n int) string {
+	return fmt.Sprintf("%d PRECEDING", n)
+}
+
+// Following returns the "<n> FOLLOWING" window frame bound
+func Following(n int) string {
+	return fmt.Sprintf("%d FOLLOWING", n)
+}
+
+// Over generates a window function call of a clause, usually an aggregate
+// Over(Sum(orders.C("total"))).PartitionBy(orders.C("user_id"))
+func Over(clause Clause) WindowClause {
+	return WindowClause{clause: clause}
+}
+
+// WindowClause is the base struct for building window function calls:
+// <clause> [IGNORE|RESPECT NULLS] OVER (PARTITION BY ... ORDER BY ... <frame>)
+type WindowClause struct {
+	clause      Clause
+	nulls       string
+	partitionBy []Clause
+	orderBy     *OrderByClause
+	frame       *FrameClause
+}
+
+// IgnoreNulls makes a value function like LAG, LEAD or FIRST_VALUE skip the
+// null values: Over(Func("LAG", x)).IgnoreNulls()
+// NOTE: it is only for the default dialect and the custom compilers, none of
+// the postgres, mysql and sqlite compilers accepts it
+func (w WindowClause) IgnoreNulls() WindowClause {
+	w.nulls = "IGNORE NULLS"
+	return w
+}
+
+// RespectNulls makes a value function like LAG, LEAD or FIRST_VALUE
+// explicitly keep the null values, which is the default
+// NOTE: postgres and sqlite do not support it
+func (w WindowClause) RespectNulls() WindowClause {
+	w.nulls = "RESPECT NULLS"
+	return w
+}
+
+// PartitionBy sets the partition of the window
+func (w WindowClause) PartitionBy(clauses ...Clause) WindowClause {
+	w.partitionBy = clauses
+	return w
+}
+
+// OrderBy sets the order of the window
+func (w WindowClause) OrderBy(columns ...Clause) WindowClause {
+	w.orderBy = &OrderByClause{columns, "ASC"}
+	return w
+}
+
+// Rows sets a ROWS BETWEEN <start> AND <end> frame to the window
+func (w WindowClause) Rows(start string, end string) WindowClause {
+	return w.setFrame("ROWS", start, end)
+}
+
+// Range sets a RANGE BETWEEN <start> AND <end> frame to the window
+func (w WindowClause) Range(start string, end string) WindowClause {
+	return w.setFrame("RANGE", start, end)
+}
+
+// setFrame sets the frame of the window, keeping its EXCLUDE option
+func (w WindowClause) setFrame(mode string, start string, end string) WindowClause {
+	frame := FrameClause{Mode: mode, Start: start, End: end}
+	if w.frame != nil {
+		frame.Exclude = w.frame.Exclude
+	}
+	w.frame = &frame
+	return w
+}
+
+// ExcludeCurrentRow excludes the current row from the window frame
+// NOTE: the window needs a frame, set by Rows() or Range()
+func (w WindowClause) ExcludeCurrentRow() WindowClause {
+	return w.exclude("CURRENT ROW")
+}
+
+// ExcludeGroup excludes the current row and its peers from the window frame
+// NOTE: the window needs a frame, set by Rows() or Range()
+func (w WindowClause) ExcludeGroup() WindowClause {
+	return w.exclude("GROUP")
+}
+
+// ExcludeTies excludes the peers of the current row from the window frame
+// NOTE: the window needs a frame, set by Rows() or Range()
+func (w WindowClause) ExcludeTies() WindowClause {
+	return w.exclude("TIES")
+}
+
+// exclude sets the EXCLUDE option of the window frame. Without a frame, it
+// is reported as an error when the window is compiled
+func (w WindowClause) exclude(exclude string) WindowClause {
+	frame := FrameClause{}
+	if w.frame != nil {
+		frame = *w.frame
+	}
+	frame.Exclude = exclude
+	w.frame = &frame
+	return w
+}
+
+// Accept calls the compiler VisitWindow function
+func (w WindowClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitWindow(context, w)
+}
+
+// FrameClause is the frame of a window
+type FrameClause struct {
+	Mode    string
+	Start   string
+	End     string
+	Exclude string
+}
@@ -0,0 +1,75 @@
+package qb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWindow(t *testing.T) {
+	orders := Table(
+		"orders",
+		Column("id", Int()),
+		Column("user_id", Int()),
+		Column("total", Int()),
+	)
+
+	window := Over(Sum(orders.C("total"))).
+		PartitionBy(orders.C("user_id")).
+		OrderBy(orders.C("id"))
+	assert.Equal(t, "SUM(orders.total) OVER (PARTITION BY orders.user_id ORDER BY orders.id ASC)", asDefSQL(window))
+	assert.Equal(t, "COUNT(orders.id) OVER ()", asDefSQL(Over(Count(orders.C("id")))))
+
+	sel := Select(orders.C("id"), window.Rows(UnboundedPreceding, CurrentRow).ExcludeCurrentRow()).From(orders)
+	statement := sel.Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id, SUM(total) OVER (PARTITION BY user_id ORDER BY id ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW EXCLUDE CURRENT ROW)\nFROM orders;", statement.SQL())
+
+	assert.Equal(t,
+		"SUM(orders.total) OVER (ORDER BY orders.id ASC RANGE BETWEEN 2 PRECEDING AND 1 FOLLOWING EXCLUDE TIES)",
+		asDefSQL(Over(Sum(orders.C("total"))).OrderBy(orders.C("id")).Range(Preceding(2), Following(1)).ExcludeTies()))
+
+	_, err := BuildStmt(NewCompilerContext(NewDialect("mysql")), sel)
+	assert.EqualError(t, err, "EXCLUDE is not supported by mysql")
+
+	// EXCLUDE needs a frame, that can also be set afterwards
+	_, err = BuildStmt(NewCompilerContext(NewDialect("postgres")), window.ExcludeTies())
+	assert.EqualError(t, err, "EXCLUDE TIES needs a window frame, use Rows() or Range()")
+	assert.Equal(t,
+		"SUM(orders.total) OVER (PARTITION BY orders.user_id ORDER BY orders.id ASC ROWS BETWEEN 1 PRECEDING AND CURRENT ROW EXCLUDE TIES)",
+		asDefSQL(window.ExcludeTies().Rows(Preceding(1), CurrentRow)))
+	statement = Select(orders.C("id"), window.Rows(UnboundedPreceding, CurrentRow)).From(orders).Build(NewDialect("mysql"))
+	assert.Equal(t, "SELECT id, SUM(total) OVER (PARTITION BY user_id ORDER BY id ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)\nFROM orders;", statement.SQL())
+}
+
+func TestWindowRunningCount(t *testing.T) {
+	orders := Table("orders", Column("id", Int()), Column("user_id", Int()))
+
+	running := As(CountStar().Over().OrderBy(orders.C("id")), "running")
+	assert.Equal(t, Over(CountStar()).OrderBy(orders.C("id")), CountStar().Over().OrderBy(orders.C("id")))
+
+	statement := Select(orders.C("id"), running).From(orders).Build(NewDialect("postgres"))
+	assert.Equal(t, "SELECT id, COUNT(*) OVER (ORDER BY id ASC) AS running\nFROM orders;", statement.SQL())
+
+	statement = Select(orders.C("id"), CountStar().Over().PartitionBy(orders.C("user_id")).OrderBy(orders.C("id"))).
+		From(orders).
+		Build(NewDialect("mysql"))
+	assert.Equal(t, "SELECT id, COUNT(*) OVER (PARTITION BY user_id ORDER BY id ASC)\nFROM orders;", statement.SQL())
+}
+
+func TestWindowNulls(t *testing.T) {
+	prices := Table("prices", Column("id", Int()), Column("price", Int()))
+	lag := Over(Func("LAG", prices.C("price"))).OrderBy(prices.C("id"))
+
+	assert.Equal(t, "LAG(prices.price) IGNORE NULLS OVER (ORDER BY prices.id ASC)", asDefSQL(lag.IgnoreNulls()))
+	assert.Equal(t, "LAG(prices.price) RESPECT NULLS OVER (ORDER BY prices.id ASC)", asDefSQL(lag.RespectNulls()))
+
+	sel := Select(prices.C("id"), lag.IgnoreNulls()).From(prices)
+	_, err := BuildStmt(NewCompilerContext(NewDialect("postgres")), sel)
+	assert.EqualError(t, err, "IGNORE NULLS is not supported by postgres")
+	_, err = BuildStmt(NewCompilerContext(NewDialect("sqlite3")), sel)
+	assert.EqualError(t, err, "IGNORE NULLS is not supported by sqlite")
+	_, err = BuildStmt(NewCompilerContext(NewDialect("mysql")), sel)
+	assert.EqualError(t, err, "IGNORE NULLS is not supported by mysql")
+
+	statement := Select(prices.C("id"), lag.RespectNulls()).From(prices).Build(NewDialect("mysql"))
+	assert.Equal(t, "SELECT id, LAG(price) RESPECT NULLS OVER (ORDER BY id ASC)\nFROM prices;", statement.SQL())
+}